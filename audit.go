@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// DecisionLog is a JSON-serialisable audit record of a single evaluation.
+// Inputs holds the values the evaluation read for the fields and value
+// fields of the conditions it evaluated; fields absent from the data, and
+// conditions skipped by short-circuiting, are omitted.
+type DecisionLog struct {
+	Timestamp   time.Time      `json:"timestamp"`
+	RuleHash    string         `json:"ruleHash"`
+	Inputs      map[string]any `json:"inputs"`
+	Matched     bool           `json:"matched"`
+	Explanation string         `json:"explanation,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// EvaluateWithLog evaluates rule and also returns a DecisionLog describing
+// the evaluation. When evaluation fails the log records the error message.
+func (e *Engine) EvaluateWithLog(rule Rule, data map[string]any) (Result, DecisionLog, error) {
+	return e.EvaluateWithLogContext(context.Background(), rule, data)
+}
+
+// EvaluateWithLogContext is EvaluateWithLog with a context for the
+// evaluation.
+func (e *Engine) EvaluateWithLogContext(ctx context.Context, rule Rule, data map[string]any) (Result, DecisionLog, error) {
+	hash, err := ruleHash(rule)
+	if err != nil {
+		return Result{}, DecisionLog{}, err
+	}
+	log := DecisionLog{
+		Timestamp: e.now().UTC(),
+		RuleHash:  hash,
+		Inputs:    make(map[string]any),
+	}
+	p, err := newPlan(rule)
+	if err != nil {
+		log.Error = err.Error()
+		return Result{}, log, err
+	}
+	p.inputs = log.Inputs
+	res, err := e.evaluateTopLevel(ctx, p, data)
+	if err != nil {
+		log.Error = err.Error()
+		return res, log, err
+	}
+	log.Matched = res.Matched
+	log.Explanation = res.Explanation
+	return res, log, nil
+}
+
+// ruleHash returns the hex SHA-256 of the rule's JSON encoding.
+func ruleHash(rule Rule) (string, error) {
+	b, err := json.Marshal(rule)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
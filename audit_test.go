@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvaluateWithLog(t *testing.T) {
+	rule := Rule{Conditions: []Condition{
+		{Field: "user.age", Op: OperatorGTE, Value: 18},
+		{Field: "spend", Op: OperatorLT, ValueField: "limit"},
+	}}
+	data := map[string]any{"user": map[string]any{"age": 30, "name": "x"}, "spend": 10, "limit": 50, "unused": true}
+	before := time.Now().UTC()
+	res, log, err := New().EvaluateWithLog(rule, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched || !log.Matched {
+		t.Errorf("expected match, got result %v log %v", res.Matched, log.Matched)
+	}
+	if log.Timestamp.Before(before) {
+		t.Errorf("timestamp %v before evaluation start %v", log.Timestamp, before)
+	}
+	if len(log.RuleHash) != 64 {
+		t.Errorf("RuleHash = %q, want 64 hex characters", log.RuleHash)
+	}
+	want := map[string]any{"user.age": 30, "spend": 10, "limit": 50}
+	if len(log.Inputs) != len(want) {
+		t.Errorf("Inputs = %v, want %v", log.Inputs, want)
+	}
+	for k, v := range want {
+		if log.Inputs[k] != v {
+			t.Errorf("Inputs[%q] = %v, want %v", k, log.Inputs[k], v)
+		}
+	}
+	if log.Explanation != res.Explanation {
+		t.Errorf("Explanation = %q, want %q", log.Explanation, res.Explanation)
+	}
+	if _, err := json.Marshal(log); err != nil {
+		t.Errorf("log not serialisable: %v", err)
+	}
+
+	_, again, err := New().EvaluateWithLog(rule, map[string]any{"user": map[string]any{"age": 1}, "spend": 1, "limit": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.RuleHash != log.RuleHash {
+		t.Error("rule hash should not depend on data")
+	}
+}
+
+func TestEvaluateWithLogGroup(t *testing.T) {
+	rule := Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+		{Condition: &Condition{Field: "role", Op: OperatorEQ, Value: "admin"}},
+		{Group: &Group{Items: []Item{{Condition: &Condition{Field: "spend", Op: OperatorLT, ValueField: "limit"}}, {Condition: &Condition{Field: "coupon", Op: OperatorExists}}}}},
+	}}}
+	_, log, err := New().EvaluateWithLog(rule, map[string]any{"role": "user", "coupon": nil, "spend": 10, "limit": 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"role": "user", "coupon": nil, "spend": 10, "limit": 50}
+	if !reflect.DeepEqual(log.Inputs, want) {
+		t.Errorf("Inputs = %v, want %v", log.Inputs, want)
+	}
+}
+
+func TestEvaluateWithLogRecordsEvaluatedValues(t *testing.T) {
+	e := New()
+	calls := 0
+	e.Resolver = FieldResolverFunc(func(path string) (any, bool, error) {
+		calls++
+		return calls * 100, path == "score", nil
+	})
+	rule := Rule{Conditions: []Condition{
+		{Field: "score", Op: OperatorGT, Value: 50},
+		{Field: "items", Op: OperatorAny, Value: Condition{Field: "qty", Op: OperatorGT, Value: 1}},
+		{Field: "tier", Op: OperatorEQ, Value: "gold"},
+		{Field: "unreached", Op: OperatorEQ, Value: 1},
+	}}
+	data := map[string]any{"items": []any{map[string]any{"qty": 2}}, "tier": "silver", "unreached": 1}
+	_, log, err := e.EvaluateWithLog(rule, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("resolver called %d times, want 1", calls)
+	}
+	want := map[string]any{"score": 100, "items": data["items"], "tier": "silver"}
+	if !reflect.DeepEqual(log.Inputs, want) {
+		t.Errorf("Inputs = %v, want %v", log.Inputs, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, log, err := e.EvaluateWithLogContext(ctx, rule, data); !errors.Is(err, context.Canceled) || log.Error == "" {
+		t.Errorf("cancelled evaluation: err = %v, log error %q", err, log.Error)
+	}
+}
+
+func TestEvaluateWithLogError(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "missing", Op: OperatorEQ, Value: 1}}}
+	_, log, err := New().EvaluateWithLog(rule, map[string]any{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if log.Error == "" || log.RuleHash == "" {
+		t.Errorf("log should record error and hash, got %+v", log)
+	}
+}
+
+func TestEvaluateWithLogClock(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	e := New()
+	e.Now = func() time.Time { return at }
+	_, log, err := e.EvaluateWithLog(Rule{}, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !log.Timestamp.Equal(at) || log.Timestamp.Location() != time.UTC {
+		t.Errorf("Timestamp = %v, want %v in UTC", log.Timestamp, at)
+	}
+}
@@ -0,0 +1,32 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+)
+
+// EvaluateBatch evaluates rule against each record, returning the results in
+// record order. Work that does not depend on the data, such as defaulting
+// the rule's logic and checking its When references, is done once for the
+// whole batch. The context is checked between records; on cancellation or
+// the first failing record the error is returned, identifying the record,
+// and no results. The ResultHook, Tracer and MaxDuration apply to every
+// record.
+func (e *Engine) EvaluateBatch(ctx context.Context, rule Rule, records []map[string]any) ([]Result, error) {
+	p, err := newPlan(rule)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(records))
+	for i, data := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := e.evaluateTopLevel(ctx, p, data)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		results[i] = res
+	}
+	return results, nil
+}
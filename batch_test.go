@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateBatch(t *testing.T) {
+	rule := Rule{Logic: LogicOR, Conditions: []Condition{
+		{Field: "role", Op: OperatorEQ, Value: "admin"},
+		{Field: "score", Op: OperatorGT, Value: 100},
+	}}
+	records := []map[string]any{
+		{"role": "admin", "score": 1},
+		{"role": "user", "score": 150},
+		{"role": "user", "score": 50},
+	}
+	results, err := New().EvaluateBatch(context.Background(), rule, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(records) {
+		t.Fatalf("got %d results, want %d", len(results), len(records))
+	}
+	for i, data := range records {
+		want, err := Evaluate(rule, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[i].Matched != want.Matched || results[i].Explanation != want.Explanation {
+			t.Errorf("record %d: got %+v, want %+v", i, results[i], want)
+		}
+	}
+
+	t.Run("empty batch", func(t *testing.T) {
+		results, err := New().EvaluateBatch(context.Background(), rule, nil)
+		if err != nil || len(results) != 0 {
+			t.Errorf("got %v, %v; want no results", results, err)
+		}
+	})
+	t.Run("failing record", func(t *testing.T) {
+		_, err := New().EvaluateBatch(context.Background(), rule, []map[string]any{records[0], {"role": "user"}})
+		if !errors.Is(err, ErrFieldNotFound) || !strings.HasPrefix(err.Error(), "record 1:") {
+			t.Errorf("error = %v, want record 1 ErrFieldNotFound", err)
+		}
+	})
+	t.Run("invalid rule", func(t *testing.T) {
+		bad := Rule{Conditions: []Condition{{Field: "a", Op: OperatorEQ, Value: 1, When: "$cond:0"}}}
+		if _, err := New().EvaluateBatch(context.Background(), bad, records); err == nil {
+			t.Error("expected error for invalid when reference")
+		}
+	})
+	t.Run("cancellation between records", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		e := New()
+		calls := 0
+		e.ResultHook = func(_ Rule, _ map[string]any, res Result) Result {
+			if calls++; calls == 2 {
+				cancel()
+			}
+			return res
+		}
+		_, err := e.EvaluateBatch(ctx, rule, records)
+		if !errors.Is(err, context.Canceled) || calls != 2 {
+			t.Errorf("error = %v after %d records, want context.Canceled after 2", err, calls)
+		}
+	})
+}
+
+func benchmarkRecords(n int) []map[string]any {
+	records := make([]map[string]any, n)
+	for i := range records {
+		records[i] = map[string]any{"age": i % 90, "country": []string{"US", "DE", "FR"}[i%3], "premium": i%2 == 0}
+	}
+	return records
+}
+
+var benchmarkRule = Rule{Conditions: []Condition{
+	{Field: "age", Op: OperatorGTE, Value: 18},
+	{Field: "country", Op: OperatorIn, Value: []any{"US", "DE"}},
+	{Field: "premium", Op: OperatorEQ, Value: true},
+}}
+
+func BenchmarkEvaluateLoop(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			e := New()
+			records := benchmarkRecords(n)
+			b.ReportAllocs()
+			for range b.N {
+				for _, data := range records {
+					if _, err := e.Evaluate(benchmarkRule, data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEvaluateBatch(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			e := New()
+			records := benchmarkRecords(n)
+			b.ReportAllocs()
+			for range b.N {
+				if _, err := e.EvaluateBatch(context.Background(), benchmarkRule, records); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
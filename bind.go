@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RuleTemplate is a rule with named parameters written as "${name}" in
+// condition fields, value fields and values, e.g.
+// {"field":"age","op":"gte","value":"${minAge}"}. Bind turns it into a
+// concrete Rule.
+type RuleTemplate struct {
+	Rule Rule `json:"rule"`
+}
+
+// placeholder matches a "${name}" parameter reference.
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Bind returns a copy of the template's rule with every placeholder replaced
+// by its parameter. A value consisting of a single placeholder takes the
+// parameter as is, keeping its type, so "${minAge}" bound to 18 compares as
+// a number; placeholders embedded in longer strings are formatted with
+// fmt.Sprint. Unbound parameters are an error naming each of them; unused
+// parameters are ignored.
+func (t RuleTemplate) Bind(params map[string]any) (Rule, error) {
+	b := binder{params: params}
+	r := t.Rule
+	r.Conditions = b.conditions(r.Conditions)
+	r.Root = b.group(r.Root)
+	if len(b.unbound) > 0 {
+		slices.Sort(b.unbound)
+		return Rule{}, fmt.Errorf("unbound template parameters: %s", strings.Join(slices.Compact(b.unbound), ", "))
+	}
+	return r, nil
+}
+
+// binder substitutes parameters, collecting the names it could not bind.
+type binder struct {
+	params  map[string]any
+	unbound []string
+}
+
+func (b *binder) conditions(conds []Condition) []Condition {
+	if conds == nil {
+		return nil
+	}
+	out := make([]Condition, len(conds))
+	for i, c := range conds {
+		out[i] = b.condition(c)
+	}
+	return out
+}
+
+func (b *binder) condition(c Condition) Condition {
+	c.Field = b.str(c.Field)
+	c.ValueField = b.str(c.ValueField)
+	c.Value = b.value(c.Value)
+	return c
+}
+
+func (b *binder) group(g *Group) *Group {
+	if g == nil {
+		return nil
+	}
+	out := &Group{Logic: g.Logic, Items: make([]Item, len(g.Items))}
+	for i, item := range g.Items {
+		if item.Condition != nil {
+			c := b.condition(*item.Condition)
+			out.Items[i].Condition = &c
+		}
+		out.Items[i].Group = b.group(item.Group)
+	}
+	return out
+}
+
+// value substitutes placeholders in v, descending into lists and maps.
+func (b *binder) value(v any) any {
+	switch x := v.(type) {
+	case string:
+		if m := placeholder.FindStringSubmatch(x); m != nil && m[0] == x {
+			p, ok := b.params[m[1]]
+			if !ok {
+				b.unbound = append(b.unbound, m[1])
+			}
+			return p
+		}
+		return b.str(x)
+	case []any:
+		out := make([]any, len(x))
+		for i, item := range x {
+			out[i] = b.value(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, item := range x {
+			out[k] = b.value(item)
+		}
+		return out
+	}
+	return v
+}
+
+// str substitutes the placeholders embedded in s.
+func (b *binder) str(s string) string {
+	return placeholder.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		p, ok := b.params[name]
+		if !ok {
+			b.unbound = append(b.unbound, name)
+			return ref
+		}
+		return fmt.Sprint(p)
+	})
+}
@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleTemplateBind(t *testing.T) {
+	tmpl := RuleTemplate{Rule: Rule{
+		Conditions: []Condition{
+			{Field: "age", Op: OperatorGTE, Value: "${minAge}"},
+			{Field: "limits.${region}", Op: OperatorGTE, Value: "${minLimit}"},
+			{Field: "tier", Op: OperatorIn, Value: []any{"${tier}", "gold"}},
+			{Field: "greeting", Op: OperatorEQ, Value: "hello ${name}"},
+		},
+	}}
+	rule, err := tmpl.Bind(map[string]any{
+		"minAge": 18, "region": "eu", "minLimit": 100, "tier": "silver", "name": "Ada", "unused": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data map[string]any
+		want bool
+	}{
+		{name: "all bound conditions met", data: map[string]any{"age": 20, "limits": map[string]any{"eu": 150}, "tier": "silver", "greeting": "hello Ada"}, want: true},
+		{name: "below bound age", data: map[string]any{"age": 17, "limits": map[string]any{"eu": 150}, "tier": "silver", "greeting": "hello Ada"}, want: false},
+		{name: "bound field path", data: map[string]any{"age": 20, "limits": map[string]any{"eu": 50, "us": 150}, "tier": "gold", "greeting": "hello Ada"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v (%s)", res.Matched, tt.want, res.Explanation)
+			}
+		})
+	}
+	if v := tmpl.Rule.Conditions[2].Value.([]any)[0]; v != "${tier}" {
+		t.Errorf("Bind modified the template: %v", v)
+	}
+}
+
+func TestRuleTemplateBindGroups(t *testing.T) {
+	tmpl := RuleTemplate{Rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+		{Condition: &Condition{Field: "role", Op: OperatorEQ, Value: "${role}"}},
+		{Group: &Group{Items: []Item{{Condition: &Condition{Field: "score", Op: OperatorGT, Value: "${minScore}"}}}}},
+	}}}}
+	rule, err := tmpl.Bind(map[string]any{"role": "admin", "minScore": 90})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Evaluate(rule, map[string]any{"role": "user", "score": 95})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Errorf("expected match: %s", res.Explanation)
+	}
+}
+
+func TestRuleTemplateUnbound(t *testing.T) {
+	tmpl := RuleTemplate{Rule: Rule{Conditions: []Condition{
+		{Field: "age", Op: OperatorGTE, Value: "${minAge}"},
+		{Field: "${scoreField}", Op: OperatorGT, Value: "${minAge}"},
+	}}}
+	_, err := tmpl.Bind(map[string]any{})
+	if err == nil {
+		t.Fatal("expected error for unbound parameters")
+	}
+	if want := "unbound template parameters: minAge, scoreField"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if _, err := tmpl.Bind(map[string]any{"minAge": 18}); err == nil || !strings.Contains(err.Error(), "scoreField") {
+		t.Errorf("error = %v, want it to name scoreField", err)
+	}
+}
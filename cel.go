@@ -0,0 +1,248 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// celOperators maps comparison operators to their CEL infix operators.
+var celOperators = map[Operator]string{
+	OperatorEQ:  "==",
+	OperatorNE:  "!=",
+	OperatorGT:  ">",
+	OperatorGTE: ">=",
+	OperatorLT:  "<",
+	OperatorLTE: "<=",
+	OperatorIn:  "in",
+}
+
+// ToCEL renders the rule as an equivalent CEL expression, e.g.
+// `age > 18 && role == "admin"`. The comparison operators, in, notin,
+// contains, startswith, endswith and regex (without embedded field
+// references) are supported, as are ValueField, Percent, "$field:" values,
+// negation, When implications and nested groups. Other operators, units,
+// transforms and value references that CEL cannot express are an error.
+func (r Rule) ToCEL() (string, error) {
+	if r.Root != nil {
+		return r.Root.toCEL(false)
+	}
+	if len(r.Conditions) == 0 {
+		return "true", nil
+	}
+	refs, err := antecedents(r.Conditions)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for i, c := range r.Conditions {
+		if refs[i] {
+			continue
+		}
+		s, err := c.toCEL()
+		if err != nil {
+			return "", err
+		}
+		if c.When != "" {
+			j, _ := condIndex(c.When)
+			ante, err := r.Conditions[j].toCEL()
+			if err != nil {
+				return "", err
+			}
+			s = "(!(" + ante + ") || " + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	switch r.Logic {
+	case LogicOR:
+		return strings.Join(parts, " || "), nil
+	case LogicNOT:
+		return "!(" + strings.Join(parts, " && ") + ")", nil
+	}
+	return strings.Join(parts, " && "), nil
+}
+
+// toCEL renders g, parenthesising it when nested within another group.
+func (g *Group) toCEL(nested bool) (string, error) {
+	parts := make([]string, 0, len(g.Items))
+	for i, item := range g.Items {
+		var s string
+		var err error
+		switch {
+		case item.Condition != nil && item.Group == nil:
+			if item.Condition.When != "" {
+				return "", fmt.Errorf("cel: group item %d: when is not supported in groups", i)
+			}
+			s, err = item.Condition.toCEL()
+		case item.Group != nil && item.Condition == nil:
+			s, err = item.Group.toCEL(true)
+		default:
+			return "", fmt.Errorf("cel: group item %d must set exactly one of condition or group", i)
+		}
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, s)
+	}
+	switch g.Logic {
+	case LogicOR:
+		if len(parts) == 0 {
+			return "false", nil
+		}
+		s := strings.Join(parts, " || ")
+		if nested && len(parts) > 1 {
+			s = "(" + s + ")"
+		}
+		return s, nil
+	case LogicNOT:
+		if len(parts) == 0 {
+			return "false", nil
+		}
+		return "!(" + strings.Join(parts, " && ") + ")", nil
+	}
+	if len(parts) == 0 {
+		return "true", nil
+	}
+	s := strings.Join(parts, " && ")
+	if nested && len(parts) > 1 {
+		s = "(" + s + ")"
+	}
+	return s, nil
+}
+
+func (c Condition) toCEL() (string, error) {
+	s, err := c.celComparison()
+	if err != nil || !c.Negate {
+		return s, err
+	}
+	return "!(" + s + ")", nil
+}
+
+// celComparison renders the condition without its negation.
+func (c Condition) celComparison() (string, error) {
+	if c.Unit != "" || c.Transform != "" || len(c.Transforms) > 0 {
+		return "", fmt.Errorf("cel: condition on %q uses a unit or transform", c.Field)
+	}
+	if c.FieldBy != nil {
+		return "", fmt.Errorf("cel: condition selects its field by discriminator %q", c.FieldBy.Discriminator)
+	}
+	field, err := celPath(c.Field)
+	if err != nil {
+		return "", err
+	}
+	value, err := c.celValue()
+	if err != nil {
+		return "", err
+	}
+	switch c.Op {
+	case OperatorContains:
+		return field + ".contains(" + value + ")", nil
+	case OperatorNotIn:
+		return "!(" + field + " in " + value + ")", nil
+	case OperatorStartsWith:
+		return field + ".startsWith(" + value + ")", nil
+	case OperatorEndsWith:
+		return field + ".endsWith(" + value + ")", nil
+	case OperatorRegex:
+		if s, ok := c.Value.(string); ok && strings.Contains(s, "{{") {
+			return "", fmt.Errorf("cel: regex on %q embeds field references", c.Field)
+		}
+		return field + ".matches(" + value + ")", nil
+	}
+	sym, ok := celOperators[c.Op]
+	if !ok {
+		return "", fmt.Errorf("cel: unsupported operator %q", c.Op)
+	}
+	return field + " " + sym + " " + value, nil
+}
+
+// celValue renders the condition's comparison operand.
+func (c Condition) celValue() (string, error) {
+	if c.ValueField != "" {
+		ref, err := celPath(c.ValueField)
+		if err != nil {
+			return "", err
+		}
+		if c.Percent != 0 {
+			return ref + " * " + celLiteralNumber(c.Percent/100), nil
+		}
+		return ref, nil
+	}
+	if s, ok := c.Value.(string); ok && strings.HasPrefix(s, fieldRefPrefix) {
+		return celPath(strings.TrimPrefix(s, fieldRefPrefix))
+	}
+	return celLiteral(c.Value)
+}
+
+// celPath renders a dot path as a CEL selector, falling back to index
+// syntax for segments that are not identifiers: a list index such as
+// items[0] for segments of decimal digits, as getValue reads slices, and a
+// string key such as labels["app-name"] otherwise.
+func celPath(path string) (string, error) {
+	parts := strings.Split(path, ".")
+	if !isCELIdent(parts[0]) {
+		return "", fmt.Errorf("cel: field %q does not start with an identifier", path)
+	}
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, p := range parts[1:] {
+		switch i, ok := sliceIndex(p, math.MaxInt); {
+		case isCELIdent(p):
+			b.WriteString("." + p)
+		case ok:
+			b.WriteString("[" + strconv.Itoa(i) + "]")
+		default:
+			b.WriteString("[" + strconv.Quote(p) + "]")
+		}
+	}
+	return b.String(), nil
+}
+
+func isCELIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || i > 0 && '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func celLiteral(v any) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case string:
+		if isValueRef(x) {
+			return "", fmt.Errorf("cel: unsupported value reference %q", x)
+		}
+		return strconv.Quote(x), nil
+	case []any:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			s, err := celLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+	if f, ok := toFloat(v); ok {
+		return celLiteralNumber(f), nil
+	}
+	return "", fmt.Errorf("cel: unsupported value %#v", v)
+}
+
+// celLiteralNumber renders whole numbers as CEL ints and others as doubles.
+func celLiteralNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
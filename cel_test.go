@@ -0,0 +1,75 @@
+package rules
+
+import "testing"
+
+func TestRuleToCEL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		want    string
+		wantErr bool
+	}{
+		{"and", Rule{Conditions: []Condition{
+			{Field: "age", Op: OperatorGT, Value: 18},
+			{Field: "role", Op: OperatorEQ, Value: "admin"},
+		}}, `age > 18 && role == "admin"`, false},
+		{"or with in", Rule{Logic: LogicOR, Conditions: []Condition{
+			{Field: "country", Op: OperatorIn, Value: []any{"FR", "DE"}},
+			{Field: "user.vip", Op: OperatorEQ, Value: true},
+		}}, `country in ["FR", "DE"] || user.vip == true`, false},
+		{"numbers and null", Rule{Conditions: []Condition{
+			{Field: "score", Op: OperatorGTE, Value: 2.5},
+			{Field: "count", Op: OperatorLT, Value: float64(10)},
+			{Field: "deleted", Op: OperatorEQ, Value: nil},
+		}}, `score >= 2.5 && count < 10 && deleted == null`, false},
+		{"field references", Rule{Conditions: []Condition{
+			{Field: "spend", Op: OperatorGTE, ValueField: "limit", Percent: 80},
+			{Field: "end", Op: OperatorGT, Value: "$field:start"},
+		}}, `spend >= limit * 0.8 && end > start`, false},
+		{"string functions and index syntax", Rule{Conditions: []Condition{
+			{Field: "email", Op: OperatorContains, Value: "@"},
+			{Field: "headers.x-id", Op: OperatorRegex, Value: "^[0-9]+$"},
+		}}, `email.contains("@") && headers["x-id"].matches("^[0-9]+$")`, false},
+		{"slice index", Rule{Conditions: []Condition{
+			{Field: "cart.items.0.sku", Op: OperatorEQ, Value: "A1"},
+			{Field: "cart.items.1.qty", Op: OperatorGT, ValueField: "limits.0"},
+		}}, `cart.items[0].sku == "A1" && cart.items[1].qty > limits[0]`, false},
+		{"implication", Rule{Conditions: []Condition{
+			{Field: "country", Op: OperatorEQ, Value: "US"},
+			{Field: "age", Op: OperatorGTE, Value: 21, When: "$cond:0"},
+			{Field: "active", Op: OperatorEQ, Value: true},
+		}}, `(!(country == "US") || age >= 21) && active == true`, false},
+		{"notin", Rule{Conditions: []Condition{{Field: "status", Op: OperatorNotIn, Value: []any{"banned"}}}}, `!(status in ["banned"])`, false},
+		{"empty", Rule{}, "true", false},
+		{"unsupported operator", Rule{Conditions: []Condition{{Field: "card", Op: OperatorLuhn}}}, "", true},
+		{"unsupported transform", Rule{Conditions: []Condition{{Field: "a", Op: OperatorEQ, Value: "x", Transform: "upper"}}}, "", true},
+		{"unsupported reference", Rule{Conditions: []Condition{{Field: "ip", Op: OperatorIn, Value: "$set:blocked"}}}, "", true},
+		{"dollar literal", Rule{Conditions: []Condition{{Field: "price", Op: OperatorEQ, Value: "$100"}}}, `price == "$100"`, false},
+		{"embedded regex field", Rule{Conditions: []Condition{{Field: "a", Op: OperatorRegex, Value: "^{{b}}$"}}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.ToCEL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToCEL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToCEL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleToCELNegation(t *testing.T) {
+	rule := Rule{Logic: LogicNOT, Conditions: []Condition{
+		{Field: "blocked", Op: OperatorEQ, Value: true},
+		{Field: "role", Op: OperatorEQ, Value: "admin", Negate: true},
+	}}
+	got, err := rule.ToCEL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `!(blocked == true && !(role == "admin"))`; got != want {
+		t.Errorf("ToCEL() = %s, want %s", got, want)
+	}
+}
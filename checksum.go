@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OperatorLuhn matches a string of digits that passes the Luhn checksum used
+// by payment card numbers. The condition value is ignored. Strings that
+// contain anything other than digits do not match.
+const OperatorLuhn Operator = "luhn"
+
+func luhn(a, _ any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for luhn")
+	}
+	if len(s) < 2 {
+		return false, nil
+	}
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			return false, nil
+		}
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0, nil
+}
+
+// OperatorIsValidIBAN matches a string that is a well-formed International
+// Bank Account Number passing the ISO 13616 mod-97 check. Spaces are ignored
+// and letters may be in either case. The condition value is ignored.
+const OperatorIsValidIBAN Operator = "is_valid_iban"
+
+// OperatorIsValidISBN matches a string that is an ISBN-10 or ISBN-13 with a
+// valid check digit. Hyphens and spaces are ignored. The condition value is
+// ignored.
+const OperatorIsValidISBN Operator = "is_valid_isbn"
+
+func isValidIBAN(a, _ any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for is_valid_iban")
+	}
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(s) < 15 || len(s) > 34 {
+		return false, nil
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case i < 2 && (c < 'A' || c > 'Z'):
+			return false, nil
+		case i >= 2 && i < 4 && (c < '0' || c > '9'):
+			return false, nil
+		case (c < '0' || c > '9') && (c < 'A' || c > 'Z'):
+			return false, nil
+		}
+	}
+	// Move the country code and check digits to the end, read letters as
+	// 10..35 and reduce modulo 97 digit by digit.
+	rem := 0
+	for _, c := range s[4:] + s[:4] {
+		if c >= 'A' {
+			rem = (rem*100 + int(c-'A'+10)) % 97
+		} else {
+			rem = (rem*10 + int(c-'0')) % 97
+		}
+	}
+	return rem == 1, nil
+}
+
+func isValidISBN(a, _ any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for is_valid_isbn")
+	}
+	s = strings.NewReplacer("-", "", " ", "").Replace(s)
+	switch len(s) {
+	case 10:
+		sum := 0
+		for i := 0; i < 10; i++ {
+			var d int
+			switch c := s[i]; {
+			case c >= '0' && c <= '9':
+				d = int(c - '0')
+			case i == 9 && (c == 'X' || c == 'x'):
+				d = 10
+			default:
+				return false, nil
+			}
+			sum += (10 - i) * d
+		}
+		return sum%11 == 0, nil
+	case 13:
+		sum := 0
+		for i := 0; i < 13; i++ {
+			c := s[i]
+			if c < '0' || c > '9' {
+				return false, nil
+			}
+			w := 1
+			if i%2 == 1 {
+				w = 3
+			}
+			sum += w * int(c-'0')
+		}
+		return sum%10 == 0, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,94 @@
+package rules
+
+import "testing"
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		name    string
+		card    any
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid visa", card: "4111111111111111", want: true},
+		{name: "valid amex", card: "378282246310005", want: true},
+		{name: "valid short", card: "79927398713", want: true},
+		{name: "invalid checksum", card: "4111111111111112", want: false},
+		{name: "non-digit", card: "4111-1111-1111-1111", want: false},
+		{name: "too short", card: "0", want: false},
+		{name: "not a string", card: 4111111111111111, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "card", Op: OperatorLuhn}}}
+			res, err := Evaluate(rule, map[string]any{"card": tt.card})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidIBAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		iban    any
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid GB", iban: "GB82WEST12345698765432", want: true},
+		{name: "valid DE with spaces", iban: "DE89 3704 0044 0532 0130 00", want: true},
+		{name: "valid lower case", iban: "fr1420041010050500013m02606", want: true},
+		{name: "wrong check digits", iban: "GB83WEST12345698765432", want: false},
+		{name: "transposed digits", iban: "GB82WEST12345698765423", want: false},
+		{name: "bad country code", iban: "1282WEST12345698765432", want: false},
+		{name: "punctuation", iban: "GB82-WEST-1234-5698-7654-32", want: false},
+		{name: "too short", iban: "GB82WEST1234", want: false},
+		{name: "not a string", iban: 42, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "iban", Op: OperatorIsValidIBAN}}}
+			res, err := Evaluate(rule, map[string]any{"iban": tt.iban})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidISBN(t *testing.T) {
+	tests := []struct {
+		name    string
+		isbn    any
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid ISBN-10", isbn: "0306406152", want: true},
+		{name: "valid ISBN-10 with X", isbn: "0-8044-2957-X", want: true},
+		{name: "valid ISBN-13", isbn: "978-0-306-40615-7", want: true},
+		{name: "valid ISBN-13 with spaces", isbn: "978 3 16 148410 0", want: true},
+		{name: "invalid ISBN-10", isbn: "0306406153", want: false},
+		{name: "invalid ISBN-13", isbn: "9780306406158", want: false},
+		{name: "X not in last place", isbn: "03064061X2", want: false},
+		{name: "wrong length", isbn: "978030640615", want: false},
+		{name: "not a string", isbn: 9780306406157, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "isbn", Op: OperatorIsValidISBN}}}
+			res, err := Evaluate(rule, map[string]any{"isbn": tt.isbn})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
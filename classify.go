@@ -0,0 +1,29 @@
+package rules
+
+// Branch pairs a rule with the label it produces when it matches.
+type Branch struct {
+	Label string `json:"label"`
+	Rule  Rule   `json:"rule"`
+}
+
+// LabeledRule is a multi-outcome rule: branches are tried in order and the
+// first matching branch's label wins, falling back to Default.
+type LabeledRule struct {
+	Branches []Branch `json:"branches"`
+	Default  string   `json:"default,omitempty"`
+}
+
+// Classify returns the label of the first branch of rule that matches data,
+// or rule.Default when none does.
+func (e *Engine) Classify(rule LabeledRule, data map[string]any) (string, error) {
+	for _, b := range rule.Branches {
+		res, err := e.Evaluate(b.Rule, data)
+		if err != nil {
+			return "", err
+		}
+		if res.Matched {
+			return b.Label, nil
+		}
+	}
+	return rule.Default, nil
+}
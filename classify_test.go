@@ -0,0 +1,37 @@
+package rules
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tiers := LabeledRule{
+		Branches: []Branch{
+			{Label: "gold", Rule: Rule{Conditions: []Condition{{Field: "spend", Op: OperatorGTE, Value: 1000}}}},
+			{Label: "silver", Rule: Rule{Conditions: []Condition{{Field: "spend", Op: OperatorGTE, Value: 100}}}},
+			{Label: "bronze", Rule: Rule{Conditions: []Condition{{Field: "spend", Op: OperatorGT, Value: 0}}}},
+		},
+		Default: "none",
+	}
+	tests := []struct {
+		spend any
+		want  string
+	}{
+		{spend: 5000, want: "gold"},
+		{spend: 1000, want: "gold"},
+		{spend: 250, want: "silver"},
+		{spend: 5, want: "bronze"},
+		{spend: 0, want: "none"},
+	}
+	for _, tt := range tests {
+		got, err := New().Classify(tiers, map[string]any{"spend": tt.spend})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Classify(spend=%v) = %q, want %q", tt.spend, got, tt.want)
+		}
+	}
+
+	if _, err := New().Classify(tiers, map[string]any{}); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
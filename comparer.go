@@ -0,0 +1,40 @@
+package rules
+
+import "reflect"
+
+// RegisterComparer registers an ordering for values of type t, such as a
+// Money or Version struct, consulted by eq, ne, gt, gte, lt and lte before
+// the built-in numeric, date and deep-equality comparisons. fn returns a
+// negative number when a < b, zero when they are equal and a positive number
+// when a > b. It is used when either operand has type t.
+func (e *Engine) RegisterComparer(t reflect.Type, fn func(a, b any) (int, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.comparers[t] = fn
+}
+
+// compareCustom orders a and b with a registered comparer for the type of a,
+// or else of b. ok is false when neither type has one.
+func (e *Engine) compareCustom(a, b any) (c int, ok bool, err error) {
+	for _, v := range []any{a, b} {
+		e.mu.RLock()
+		fn, found := e.comparers[reflect.TypeOf(v)]
+		e.mu.RUnlock()
+		if found {
+			c, err = fn(a, b)
+			return c, true, err
+		}
+	}
+	return 0, false, nil
+}
+
+// comparing wraps a built-in comparison operator to use a registered
+// comparer when one applies, matching when test accepts its result.
+func (e *Engine) comparing(test func(int) bool, fn func(any, any) (bool, error)) func(any, any) (bool, error) {
+	return func(a, b any) (bool, error) {
+		if c, ok, err := e.compareCustom(a, b); ok {
+			return err == nil && test(c), err
+		}
+		return fn(a, b)
+	}
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type money struct {
+	Cents    int64
+	Currency string
+}
+
+func compareMoney(a, b any) (int, error) {
+	x, okx := a.(money)
+	y, oky := b.(money)
+	if !okx || !oky {
+		return 0, fmt.Errorf("cannot compare %v with %v", a, b)
+	}
+	if x.Currency != y.Currency {
+		return 0, fmt.Errorf("currency mismatch: %s vs %s", x.Currency, y.Currency)
+	}
+	return cmp.Compare(x.Cents, y.Cents), nil
+}
+
+func TestRegisterComparer(t *testing.T) {
+	e := New()
+	e.RegisterComparer(reflect.TypeOf(money{}), compareMoney)
+
+	price := money{Cents: 1999, Currency: "EUR"}
+	tests := []struct {
+		name    string
+		op      Operator
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "gt smaller", op: OperatorGT, value: money{1500, "EUR"}, want: true},
+		{name: "gt equal", op: OperatorGT, value: money{1999, "EUR"}, want: false},
+		{name: "gte equal", op: OperatorGTE, value: money{1999, "EUR"}, want: true},
+		{name: "lt larger", op: OperatorLT, value: money{2000, "EUR"}, want: true},
+		{name: "lte smaller", op: OperatorLTE, value: money{1000, "EUR"}, want: false},
+		{name: "eq", op: OperatorEQ, value: money{1999, "EUR"}, want: true},
+		{name: "ne", op: OperatorNE, value: money{1999, "EUR"}, want: false},
+		{name: "comparer error", op: OperatorGT, value: money{1500, "USD"}, wantErr: true},
+		{name: "value of other type", op: OperatorEQ, value: 1999, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "price", Op: tt.op, Value: tt.value}}}
+			res, err := e.Evaluate(rule, map[string]any{"price": price})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+
+	t.Run("other types use the default path", func(t *testing.T) {
+		rule := Rule{Conditions: []Condition{{Field: "n", Op: OperatorGT, Value: 1}}}
+		res, err := e.Evaluate(rule, map[string]any{"n": 2})
+		if err != nil || !res.Matched {
+			t.Errorf("got %v, %v; want match", res.Matched, err)
+		}
+	})
+}
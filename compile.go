@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// CompiledRule is a rule prepared by Engine.Compile for repeated
+// evaluation. It is safe for concurrent use.
+type CompiledRule struct {
+	e *Engine
+	p *plan
+}
+
+// Compile validates rule, as Validate does, and prepares it for hot paths:
+// its logic is normalised and its When references checked once, the
+// implementations of its operators are looked up once, and its regex
+// patterns are compiled into the engine's cache. Operators the rule uses
+// that are registered again after Compile keep their earlier
+// implementation; operators chosen by the data through OpField are still
+// looked up on each evaluation.
+func (e *Engine) Compile(rule Rule) (*CompiledRule, error) {
+	if err := e.Validate(rule); err != nil {
+		return nil, err
+	}
+	p, err := newPlan(rule)
+	if err != nil {
+		return nil, err
+	}
+	conds := rule.Conditions
+	if rule.Root != nil {
+		conds = rule.Root.conditions(slices.Clone(conds))
+	}
+	p.ops = make(map[Operator]operator)
+	for _, c := range conds {
+		if c.Op == "" {
+			continue
+		}
+		p.ops[c.Op] = e.lookupOperator(c.Op)
+		if err := e.precompile(c); err != nil {
+			return nil, err
+		}
+	}
+	return &CompiledRule{e: e, p: p}, nil
+}
+
+// precompile caches the regex patterns of c, if its value is literal.
+func (e *Engine) precompile(c Condition) error {
+	if c.ValueField != "" || isValueRef(c.Value) {
+		return nil
+	}
+	var patterns []string
+	switch c.Op {
+	case OperatorRegex:
+		if s, ok := c.Value.(string); ok && !fieldPlaceholder.MatchString(s) {
+			patterns = append(patterns, s)
+		}
+	case OperatorMatchesAtLeast:
+		if spec, ok := c.Value.(map[string]any); ok {
+			list, _ := spec["patterns"].([]any)
+			for _, p := range list {
+				if s, ok := p.(string); ok {
+					patterns = append(patterns, s)
+				}
+			}
+		}
+	}
+	for _, p := range patterns {
+		if _, err := e.compileCached(p); err != nil {
+			return fmt.Errorf("field %q: %w", c.Field, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates the compiled rule against data, as
+// Engine.EvaluateWithContext does.
+func (r *CompiledRule) Evaluate(ctx context.Context, data map[string]any) (Result, error) {
+	return r.e.evaluateTopLevel(ctx, r.p, data)
+}
+
+// Rule returns the rule r was compiled from.
+func (r *CompiledRule) Rule() Rule {
+	return r.p.rule
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	e := New()
+	e.Register("approx", func(a, b any) (bool, error) { return equalWithin(a, b, 1, false), nil })
+	rule := Rule{Logic: LogicOR, Conditions: []Condition{
+		{Field: "code", Op: OperatorRegex, Value: `^[A-Z]{3}-\d+$`},
+		{Field: "score", Op: "approx", Value: 10},
+		{Field: "tags", Op: OperatorAny, Value: map[string]any{"conditions": []any{map[string]any{"field": "name", "op": "eq", "value": "vip"}}}},
+	}}
+	compiled, err := e.Compile(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := e.regexps.Load(`^[A-Z]{3}-\d+$`); !ok {
+		t.Error("regex pattern not cached at compile time")
+	}
+	tests := []struct {
+		name string
+		data map[string]any
+	}{
+		{name: "regex match", data: map[string]any{"code": "ABC-12", "score": 0, "tags": []any{}}},
+		{name: "custom operator match", data: map[string]any{"code": "x", "score": 10.5, "tags": []any{}}},
+		{name: "nested rule match", data: map[string]any{"code": "x", "score": 0, "tags": []any{map[string]any{"name": "vip"}}}},
+		{name: "no match", data: map[string]any{"code": "x", "score": 0, "tags": []any{map[string]any{"name": "new"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compiled.Evaluate(context.Background(), tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := e.Evaluate(rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Matched != want.Matched || got.Explanation != want.Explanation {
+				t.Errorf("compiled = %+v, uncompiled = %+v", got, want)
+			}
+		})
+	}
+
+	e.Register("approx", func(a, b any) (bool, error) { return false, errors.New("replaced") })
+	if _, err := compiled.Evaluate(context.Background(), tests[1].data); err != nil {
+		t.Errorf("compiled rule should keep the operator resolved at compile time: %v", err)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want error
+	}{
+		{name: "unknown operator", rule: Rule{Conditions: []Condition{{Field: "a", Op: "approx"}}}, want: ErrUnknownOperator},
+		{name: "invalid regex", rule: Rule{Conditions: []Condition{{Field: "a", Op: OperatorRegex, Value: "("}}}},
+		{name: "invalid k-of-n pattern", rule: Rule{Conditions: []Condition{{Field: "a", Op: OperatorMatchesAtLeast, Value: map[string]any{"patterns": []any{"["}, "k": 1}}}}},
+		{name: "invalid when", rule: Rule{Conditions: []Condition{{Field: "a", Op: OperatorEQ, When: "$cond:5"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Compile(tt.rule)
+			if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkCompiledRule(b *testing.B) {
+	for _, n := range []int{100, 1000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			e := New()
+			compiled, err := e.Compile(benchmarkRule)
+			if err != nil {
+				b.Fatal(err)
+			}
+			records := benchmarkRecords(n)
+			ctx := context.Background()
+			b.ReportAllocs()
+			for range b.N {
+				for _, data := range records {
+					if _, err := compiled.Evaluate(ctx, data); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
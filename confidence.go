@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// OperatorSimilar compares strings by normalised Levenshtein similarity:
+// 1 for identical strings down to 0 for entirely different ones. Boolean
+// evaluation matches at SimilarThreshold or above; Confidence uses the
+// similarity itself.
+const OperatorSimilar Operator = "similar"
+
+// SimilarThreshold is the similarity at which OperatorSimilar matches in
+// boolean evaluation.
+const SimilarThreshold = 0.8
+
+// RegisterFuzzy registers a fuzzy scorer for op, returning a confidence
+// between 0 and 1. Confidence uses the scorer in place of the operator's
+// boolean result; ordinary evaluation is unaffected.
+func (e *Engine) RegisterFuzzy(op Operator, fn func(fieldVal, condVal any) (float64, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fuzzy[op] = fn
+}
+
+// Confidence evaluates rule as a fuzzy rule, returning a confidence between
+// 0 and 1. Conditions with a fuzzy scorer (see RegisterFuzzy) contribute
+// their score; all others contribute 1 when they match and 0 when they
+// don't, negated conditions contribute 1 minus their score. AND rules combine
+// scores with min and OR rules with max, so an exact condition acts as a
+// hard gate under AND; NOT rules take 1 minus the AND score. A rule without
+// conditions has confidence 1. Groups combine their items the same way.
+//
+// Conditions are resolved as in Evaluate: FieldBy and OpField choose the
+// field and operator, including whether it is fuzzy, and a missing field
+// under MissingAsNoMatch scores 0. Conditions referenced by a When are
+// evaluated exactly and do not contribute, and an implication whose
+// antecedent did not match contributes 1.
+func (e *Engine) Confidence(rule Rule, data map[string]any) (float64, error) {
+	ctx := context.Background()
+	st := &evalState{}
+	if rule.Root != nil {
+		return e.groupConfidence(ctx, st, rule.Root, data)
+	}
+	if len(rule.Conditions) == 0 {
+		return 1, nil
+	}
+	p, err := newPlan(rule)
+	if err != nil {
+		return 0, err
+	}
+	results := make([]bool, len(rule.Conditions))
+	var total float64
+	scored := false
+	for i, c := range rule.Conditions {
+		if p.antecedents[i] {
+			matched, _, err := e.evalImplication(ctx, st, c, results, data)
+			if err != nil {
+				return 0, err
+			}
+			results[i] = matched
+			continue
+		}
+		score := 1.0
+		if j, _ := condIndex(c.When); c.When == "" || results[j] {
+			if score, err = e.conditionConfidence(ctx, st, c, data); err != nil {
+				return 0, err
+			}
+		}
+		switch {
+		case !scored:
+			total, scored = score, true
+		case p.logic == LogicOR:
+			total = max(total, score)
+		default:
+			total = min(total, score)
+		}
+	}
+	if p.logic == LogicNOT {
+		return 1 - total, nil
+	}
+	return total, nil
+}
+
+func (e *Engine) groupConfidence(ctx context.Context, st *evalState, g *Group, data map[string]any) (float64, error) {
+	total := 1.0
+	if g.Logic == LogicOR {
+		total = 0
+	}
+	for i, item := range g.Items {
+		var score float64
+		var err error
+		switch {
+		case item.Condition != nil && item.Group == nil:
+			if item.Condition.When != "" {
+				return 0, fmt.Errorf("group item %d: when is not supported in groups", i)
+			}
+			score, err = e.conditionConfidence(ctx, st, *item.Condition, data)
+		case item.Group != nil && item.Condition == nil:
+			score, err = e.groupConfidence(ctx, st, item.Group, data)
+		default:
+			err = fmt.Errorf("group item %d must set exactly one of condition or group", i)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if g.Logic == LogicOR {
+			total = max(total, score)
+		} else {
+			total = min(total, score)
+		}
+	}
+	if g.Logic == LogicNOT {
+		return 1 - total, nil
+	}
+	return total, nil
+}
+
+func (e *Engine) conditionConfidence(ctx context.Context, st *evalState, c Condition, data map[string]any) (float64, error) {
+	c, err := e.selectCondition(c, data)
+	if err != nil {
+		return 0, err
+	}
+	e.mu.RLock()
+	fn, ok := e.fuzzy[c.Op]
+	e.mu.RUnlock()
+	if ok {
+		v, want, err := e.operands(ctx, st, c, data)
+		var missing *missingFieldError
+		if e.opts.MissingFieldBehavior == MissingAsNoMatch && errors.As(err, &missing) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		score, err := fn(v, want)
+		if c.Negate {
+			score = 1 - score
+		}
+		return score, err
+	}
+	matched, _, err := e.evalCondition(ctx, st, c, data)
+	if err != nil || !matched {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func similar(a, b any) (bool, error) {
+	score, err := similarity(a, b)
+	return score >= SimilarThreshold, err
+}
+
+// similarity returns 1 minus the Levenshtein distance between two strings
+// divided by the longer string's length in runes.
+func similarity(a, b any) (float64, error) {
+	sa, oka := a.(string)
+	sb, okb := b.(string)
+	if !oka || !okb {
+		return 0, fmt.Errorf("type mismatch for similar")
+	}
+	ra, rb := []rune(sa), []rune(sb)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1, nil
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest), nil
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,156 @@
+package rules
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConfidence(t *testing.T) {
+	fuzzyName := Condition{Field: "name", Op: OperatorSimilar, Value: "Jonathan"}
+	exactCountry := Condition{Field: "country", Op: OperatorEQ, Value: "US"}
+	tests := []struct {
+		name string
+		rule Rule
+		data map[string]any
+		want float64
+	}{
+		{
+			name: "and takes min of fuzzy and exact",
+			rule: Rule{Conditions: []Condition{fuzzyName, exactCountry}},
+			data: map[string]any{"name": "Jonathon", "country": "US"},
+			want: 0.875,
+		},
+		{
+			name: "and with failing exact condition",
+			rule: Rule{Conditions: []Condition{fuzzyName, exactCountry}},
+			data: map[string]any{"name": "Jonathan", "country": "CA"},
+			want: 0,
+		},
+		{
+			name: "or takes max",
+			rule: Rule{Conditions: []Condition{fuzzyName, exactCountry}, Logic: LogicOR},
+			data: map[string]any{"name": "Jon", "country": "CA"},
+			want: 0.375,
+		},
+		{
+			name: "or with matching exact condition",
+			rule: Rule{Conditions: []Condition{fuzzyName, exactCountry}, Logic: LogicOR},
+			data: map[string]any{"name": "Jon", "country": "US"},
+			want: 1,
+		},
+		{
+			name: "negated fuzzy condition",
+			rule: Rule{Conditions: []Condition{{Field: "name", Op: OperatorSimilar, Value: "Jonathan", Negate: true}}},
+			data: map[string]any{"name": "Jonathon"},
+			want: 0.125,
+		},
+		{
+			name: "not logic inverts the and score",
+			rule: Rule{Conditions: []Condition{fuzzyName, exactCountry}, Logic: LogicNOT},
+			data: map[string]any{"name": "Jonathon", "country": "US"},
+			want: 0.125,
+		},
+		{
+			name: "empty rule",
+			rule: Rule{},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().Confidence(tt.rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Confidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfidenceResolvesConditions(t *testing.T) {
+	premium := Condition{Field: "premium", Op: OperatorEQ, Value: true}
+	implied := Condition{Field: "name", Op: OperatorSimilar, Value: "Jonathan", When: "$cond:0"}
+	tests := []struct {
+		name string
+		rule Rule
+		data map[string]any
+		want float64
+	}{
+		{
+			name: "field by discriminator",
+			rule: Rule{Conditions: []Condition{{FieldBy: &FieldSelector{Discriminator: "kind", Paths: map[string]string{"person": "name"}}, Op: OperatorSimilar, Value: "Jonathan"}}},
+			data: map[string]any{"kind": "person", "name": "Jonathon"},
+			want: 0.875,
+		},
+		{
+			name: "operator from field",
+			rule: Rule{Conditions: []Condition{{Field: "name", OpField: "match", Value: "Jonathan"}}},
+			data: map[string]any{"match": "similar", "name": "Jonathon"},
+			want: 0.875,
+		},
+		{
+			name: "missing fuzzy field",
+			rule: Rule{Conditions: []Condition{{Field: "name", Op: OperatorSimilar, Value: "Jonathan"}, {Field: "country", Op: OperatorEQ, Value: "US"}}, Logic: LogicOR},
+			data: map[string]any{"country": "CA"},
+			want: 0,
+		},
+		{
+			name: "implication with unmet antecedent",
+			rule: Rule{Conditions: []Condition{premium, implied}},
+			data: map[string]any{"premium": false, "name": "Jon"},
+			want: 1,
+		},
+		{
+			name: "implication with met antecedent",
+			rule: Rule{Conditions: []Condition{premium, implied}},
+			data: map[string]any{"premium": true, "name": "Jonathon"},
+			want: 0.875,
+		},
+	}
+	e := NewWithOptions(Options{MissingFieldBehavior: MissingAsNoMatch})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Confidence(tt.rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Confidence = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarBoolean(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "name", Op: OperatorSimilar, Value: "Jonathan"}}}
+	for name, want := range map[string]bool{"Jonathan": true, "Jonathon": true, "Jon": false, "": false} {
+		res, err := Evaluate(rule, map[string]any{"name": name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != want {
+			t.Errorf("similar(%q): Matched = %v, want %v", name, res.Matched, want)
+		}
+	}
+	if _, err := Evaluate(rule, map[string]any{"name": 1}); err == nil {
+		t.Error("expected type mismatch error")
+	}
+}
+
+func TestRegisterFuzzy(t *testing.T) {
+	e := New()
+	e.RegisterFuzzy(OperatorGTE, func(a, b any) (float64, error) {
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		return math.Min(1, fa/fb), nil
+	})
+	got, err := e.Confidence(Rule{Conditions: []Condition{{Field: "score", Op: OperatorGTE, Value: 200}}}, map[string]any{"score": 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0.75 {
+		t.Errorf("Confidence = %v, want 0.75", got)
+	}
+}
@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+)
+
+// OperatorInControl matches when the last element of a numeric series field
+// lies within control limits derived from the elements before it: their mean
+// ± k sample standard deviations, as in statistical process control. The
+// value gives the parameters as {"k":3,"window":20}: k defaults to 3 and
+// window, when set, limits the baseline to that many elements preceding the
+// last. The baseline needs at least two elements.
+const OperatorInControl Operator = "in_control"
+
+func inControl(a, b any) (bool, error) {
+	items, ok := a.([]any)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for in_control")
+	}
+	k, window, err := controlParams(b)
+	if err != nil {
+		return false, err
+	}
+	series := make([]float64, len(items))
+	for i, item := range items {
+		f, ok := toFloat(item)
+		if !ok {
+			return false, fmt.Errorf("type mismatch for in_control: element %d is not a number", i)
+		}
+		series[i] = f
+	}
+	if len(series) < 3 {
+		return false, fmt.Errorf("in_control requires a series of at least 3 numbers")
+	}
+	last := series[len(series)-1]
+	baseline := series[:len(series)-1]
+	if window > 0 && window < len(baseline) {
+		baseline = baseline[len(baseline)-window:]
+	}
+	mean, sd := meanStddev(baseline)
+	return math.Abs(last-mean) <= k*sd, nil
+}
+
+// controlParams reads in_control's {"k":..,"window":..} value.
+func controlParams(b any) (k float64, window int, err error) {
+	k = 3
+	if b == nil {
+		return k, 0, nil
+	}
+	spec, ok := b.(map[string]any)
+	if !ok {
+		return 0, 0, fmt.Errorf(`in_control requires {"k":N,"window":N} value`)
+	}
+	if v, ok := spec["k"]; ok {
+		if k, ok = toFloat(v); !ok || k <= 0 {
+			return 0, 0, fmt.Errorf("in_control k must be a positive number")
+		}
+	}
+	if v, ok := spec["window"]; ok {
+		w, ok := toFloat(v)
+		if !ok || w < 2 || w != math.Trunc(w) {
+			return 0, 0, fmt.Errorf("in_control window must be a whole number of at least 2")
+		}
+		window = int(w)
+	}
+	return k, window, nil
+}
+
+// meanStddev returns the mean and sample standard deviation of xs, which
+// holds at least two values.
+func meanStddev(xs []float64) (mean, sd float64) {
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(xs)-1))
+}
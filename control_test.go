@@ -0,0 +1,44 @@
+package rules
+
+import "testing"
+
+func TestInControl(t *testing.T) {
+	stable := []any{10.0, 10.2, 9.8, 10.1, 9.9, 10.0}
+	tests := []struct {
+		name    string
+		series  any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		// Baseline mean 10, sample stddev ~0.158: limits ~[9.53, 10.47].
+		{name: "in control", series: append(stable[:5:5], 10.3), want: true},
+		{name: "upper limit exceeded", series: append(stable[:5:5], 10.6), want: false},
+		{name: "lower limit exceeded", series: append(stable[:5:5], 9.4), want: false},
+		{name: "tighter k", series: append(stable[:5:5], 10.3), value: map[string]any{"k": 1}, want: false},
+		{name: "looser k", series: append(stable[:5:5], 10.6), value: map[string]any{"k": 4}, want: true},
+		{name: "window drops early drift", series: []any{50, 60, 10.0, 10.2, 9.8, 10.1, 9.9, 10.3}, value: map[string]any{"window": 5}, want: true},
+		{name: "without window early drift widens limits", series: []any{50, 60, 10.0, 10.2, 9.8, 10.1, 9.9, 30}, want: true},
+		{name: "flat baseline", series: []any{5, 5, 5, 5}, want: true},
+		{name: "flat baseline shift", series: []any{5, 5, 5, 5.1}, want: false},
+		{name: "numeric strings", series: []any{"10", "10.2", "9.8", "10.1"}, want: true},
+		{name: "too short", series: []any{1, 2}, wantErr: true},
+		{name: "non-numeric element", series: []any{1, "x", 2}, wantErr: true},
+		{name: "not a slice", series: 10, wantErr: true},
+		{name: "bad k", series: stable, value: map[string]any{"k": -1}, wantErr: true},
+		{name: "bad window", series: stable, value: map[string]any{"window": 1.5}, wantErr: true},
+		{name: "bad value", series: stable, value: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "readings", Op: OperatorInControl, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"readings": tt.series})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
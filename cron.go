@@ -0,0 +1,170 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OperatorCronNextWithin matches a cron schedule field whose next run after
+// now falls within the duration given as the condition value, e.g.
+// {"field":"backup_schedule","op":"cron_next_within","value":"1h"}. The
+// value takes the same durations as OperatorAgeGT and the window's end is
+// inclusive. Schedules are evaluated in the engine's Location; see parseCron
+// for the syntax.
+const OperatorCronNextWithin Operator = "cron_next_within"
+
+func (e *Engine) cronNextWithin(a, b any) (bool, error) {
+	spec, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for cron_next_within")
+	}
+	s, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("cron_next_within requires a duration value")
+	}
+	years, months, days, d, err := parseAge(s)
+	if err != nil {
+		return false, fmt.Errorf("cron_next_within: %w", err)
+	}
+	sched, err := parseCron(spec)
+	if err != nil {
+		return false, err
+	}
+	now := e.now().In(e.location())
+	next, ok := sched.next(now)
+	if !ok {
+		return false, nil
+	}
+	return !next.After(now.AddDate(years, months, days).Add(d)), nil
+}
+
+// cronSchedule is a parsed five-field cron expression: the allowed minutes,
+// hours, days of the month, months and days of the week (Sunday is 0).
+type cronSchedule struct {
+	minute, hour, dom, month, dow []bool
+	// domAny and dowAny record an unrestricted ("*") day field; when both
+	// day fields are restricted, a day matching either one qualifies.
+	domAny, dowAny bool
+}
+
+// cronFields gives the range of each cron field, in order.
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// parseCron parses a standard five-field cron expression,
+// "minute hour day-of-month month day-of-week", such as "*/15 9-17 * * 1-5".
+// Each field is "*" or a comma-separated list of values and ranges
+// ("1-5"), each optionally stepped ("*/15", "10-30/5"). Days of the week
+// run from 0 (Sunday) to 7 (also Sunday). Names and macros such as "@daily"
+// are not supported.
+func parseCron(spec string) (*cronSchedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields", spec)
+	}
+	sets := make([][]bool, len(parts))
+	for i, part := range parts {
+		f := cronFields[i]
+		set, err := parseCronField(part, f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s: %w", spec, f.name, err)
+		}
+		sets[i] = set
+	}
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+	return &cronSchedule{
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		domAny: parts[2] == "*", dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the values from min to max, indexed by value, that
+// field allows.
+func parseCronField(field string, min, max int) ([]bool, error) {
+	set := make([]bool, max+1)
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, stepped := strings.Cut(item, "/")
+		step := 1
+		if stepped {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return nil, fmt.Errorf("invalid value %q", loStr)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return nil, fmt.Errorf("invalid value %q", hiStr)
+				}
+			} else if stepped {
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return nil, fmt.Errorf("%q out of range %d-%d", rng, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// next returns the first time after t at which s fires, searching up to five
+// years ahead; ok is false when there is none, as for "0 0 30 2 *".
+func (s *cronSchedule) next(t time.Time) (next time.Time, ok bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case !s.month[m]:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !s.hour[t.Hour()]:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !s.minute[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayMatches applies cron's day rule: when both day fields are restricted,
+// either may match.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom[t.Day()], s.dow[t.Weekday()]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNextWithin(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 20, 30, 0, time.UTC) // a Tuesday
+	tests := []struct {
+		name    string
+		cron    any
+		window  any
+		want    bool
+		wantErr bool
+	}{
+		{name: "every 15 minutes", cron: "*/15 * * * *", window: "1h", want: true},
+		{name: "hourly at :30 within 10m", cron: "30 * * * *", window: "10m", want: true},
+		{name: "hourly at :15 not within 10m", cron: "15 * * * *", window: "10m", want: false},
+		{name: "window end is inclusive", cron: "30 * * * *", window: "9m30s", want: true},
+		{name: "current minute is not next", cron: "20 14 * * *", window: "1h", want: false},
+		{name: "daily at 15:00", cron: "0 15 * * *", window: "1h", want: true},
+		{name: "daily at 02:00", cron: "0 2 * * *", window: "1h", want: false},
+		{name: "daily at 02:00 within a day", cron: "0 2 * * *", window: "1d", want: true},
+		{name: "weekdays only", cron: "0 9 * * 1-5", window: "20h", want: true},
+		{name: "weekends only", cron: "0 9 * * 0,6", window: "1d", want: false},
+		{name: "sunday as 7", cron: "0 9 * * 7", window: "5d", want: true},
+		{name: "list and range", cron: "0,45 8-15 * * *", window: "30m", want: true},
+		{name: "day of month", cron: "0 0 1 * *", window: "3w", want: false},
+		{name: "day of month within a month", cron: "0 0 1 * *", window: "1mo", want: true},
+		{name: "either day field", cron: "0 0 13 * 3", window: "1d", want: true},
+		{name: "month", cron: "0 0 1 1 *", window: "30d", want: false},
+		{name: "stepped range", cron: "10-50/20 14 * * *", window: "10m", want: true},
+		{name: "impossible date never runs", cron: "0 0 30 2 *", window: "10y", want: false},
+		{name: "too few fields", cron: "* * * *", window: "1h", wantErr: true},
+		{name: "out of range", cron: "60 * * * *", window: "1h", wantErr: true},
+		{name: "reversed range", cron: "* 5-2 * * *", window: "1h", wantErr: true},
+		{name: "bad step", cron: "*/0 * * * *", window: "1h", wantErr: true},
+		{name: "names unsupported", cron: "0 0 * * MON", window: "1h", wantErr: true},
+		{name: "bad window", cron: "* * * * *", window: "soon", wantErr: true},
+		{name: "not a string", cron: 5, window: "1h", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			e.Now = func() time.Time { return now }
+			rule := Rule{Conditions: []Condition{{Field: "schedule", Op: OperatorCronNextWithin, Value: tt.window}}}
+			res, err := e.Evaluate(rule, map[string]any{"schedule": tt.cron})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+
+	t.Run("location", func(t *testing.T) {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		if err != nil {
+			t.Skip(err)
+		}
+		e := New()
+		e.Now = func() time.Time { return now } // 23:20 in Tokyo
+		e.Location = tokyo
+		rule := Rule{Conditions: []Condition{{Field: "schedule", Op: OperatorCronNextWithin, Value: "1h"}}}
+		res, err := e.Evaluate(rule, map[string]any{"schedule": "0 0 * * *"})
+		if err != nil || !res.Matched {
+			t.Errorf("got %v, %v; want Tokyo midnight within the hour", res.Matched, err)
+		}
+	})
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	from := time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		cron string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1", time.Date(2027, 1, 4, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cron, func(t *testing.T) {
+			s, err := parseCron(tt.cron)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := s.next(from)
+			if !ok || !got.Equal(tt.want) {
+				t.Errorf("next = %v, %v; want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+)
+
+// OperatorCurve compares a numeric field against a threshold that varies
+// with another field along a piecewise-linear curve:
+//
+//	{"field":"limit_used","op":"curve","value":{"xField":"tier","curve":[[0,100],[10,500]],"op":"lte"}}
+//
+// The curve's [x, y] breakpoints must have strictly increasing x. The
+// threshold is interpolated linearly between the breakpoints either side of
+// xField's value and held at the end values beyond them, so above a tier of
+// 10 the limit stays 500. "op" says how the field compares with the
+// threshold and defaults to lte.
+const OperatorCurve Operator = "curve"
+
+func (e *Engine) curve(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	spec, _ := c.Value.(map[string]any)
+	xField, _ := spec["xField"].(string)
+	if xField == "" {
+		return false, fmt.Errorf(`curve requires {"xField":..,"curve":[[x,y],...]} value`)
+	}
+	points, err := curvePoints(spec["curve"])
+	if err != nil {
+		return false, err
+	}
+	op := OperatorLTE
+	if s, ok := spec["op"].(string); ok {
+		op = Operator(s)
+	}
+	xv, err := e.field(data, xField)
+	if err != nil {
+		return false, err
+	}
+	x, ok := toFloat(xv)
+	if !ok {
+		return false, fmt.Errorf("curve requires a number in field %q, got %v", e.fieldRef(xField), xv)
+	}
+	v, err := e.field(data, c.Field)
+	if err != nil {
+		return false, err
+	}
+	st.input(xField, xv)
+	st.input(c.Field, v)
+	want := interpolate(points, x)
+	st.resolved(v, want)
+	return e.apply(ctx, st, op, v, want, data)
+}
+
+// curvePoints parses a curve's [[x, y], ...] breakpoints.
+func curvePoints(v any) ([][2]float64, error) {
+	items, ok := v.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("curve requires at least one [x, y] breakpoint")
+	}
+	points := make([][2]float64, len(items))
+	for i, item := range items {
+		pair, _ := item.([]any)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("curve breakpoint %d must be [x, y]", i)
+		}
+		x, okx := toFloat(pair[0])
+		y, oky := toFloat(pair[1])
+		if !okx || !oky {
+			return nil, fmt.Errorf("curve breakpoint %d must be numeric", i)
+		}
+		if i > 0 && x <= points[i-1][0] {
+			return nil, fmt.Errorf("curve breakpoints must have strictly increasing x")
+		}
+		points[i] = [2]float64{x, y}
+	}
+	return points, nil
+}
+
+// interpolate returns the curve's y at x, clamped to the end values.
+func interpolate(points [][2]float64, x float64) float64 {
+	if x <= points[0][0] {
+		return points[0][1]
+	}
+	for i := 1; i < len(points); i++ {
+		x0, y0, x1, y1 := points[i-1][0], points[i-1][1], points[i][0], points[i][1]
+		if x <= x1 {
+			return y0 + (y1-y0)*(x-x0)/(x1-x0)
+		}
+	}
+	return points[len(points)-1][1]
+}
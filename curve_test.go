@@ -0,0 +1,53 @@
+package rules
+
+import "testing"
+
+func TestCurve(t *testing.T) {
+	curve := []any{[]any{0, 100}, []any{10, 500}, []any{20, 600}}
+	spec := func(op string) map[string]any {
+		s := map[string]any{"xField": "tier", "curve": curve}
+		if op != "" {
+			s["op"] = op
+		}
+		return s
+	}
+	tests := []struct {
+		name    string
+		value   any
+		tier    any
+		used    any
+		want    bool
+		wantErr bool
+	}{
+		{name: "first breakpoint: at threshold", value: spec(""), tier: 0, used: 100, want: true},
+		{name: "first breakpoint: over", value: spec(""), tier: 0, used: 101, want: false},
+		{name: "middle breakpoint", value: spec(""), tier: 10, used: 500, want: true},
+		{name: "last breakpoint", value: spec(""), tier: 20, used: 601, want: false},
+		{name: "interpolated: at threshold", value: spec(""), tier: 5, used: 300, want: true},
+		{name: "interpolated: over", value: spec(""), tier: 5, used: 300.5, want: false},
+		{name: "interpolated second segment", value: spec(""), tier: 12.5, used: 525, want: true},
+		{name: "below curve holds first value", value: spec(""), tier: -4, used: 100, want: true},
+		{name: "above curve holds last value", value: spec(""), tier: 99, used: 601, want: false},
+		{name: "custom op", value: spec("gt"), tier: 5, used: 301, want: true},
+		{name: "custom op: not above", value: spec("gt"), tier: 5, used: 300, want: false},
+		{name: "single breakpoint", value: map[string]any{"xField": "tier", "curve": []any{[]any{0, 50}}}, tier: 7, used: 50, want: true},
+		{name: "non-numeric x", value: spec(""), tier: "gold", used: 1, wantErr: true},
+		{name: "missing xField", value: map[string]any{"curve": curve}, tier: 1, used: 1, wantErr: true},
+		{name: "empty curve", value: map[string]any{"xField": "tier", "curve": []any{}}, tier: 1, used: 1, wantErr: true},
+		{name: "malformed breakpoint", value: map[string]any{"xField": "tier", "curve": []any{[]any{0}}}, tier: 1, used: 1, wantErr: true},
+		{name: "decreasing x", value: map[string]any{"xField": "tier", "curve": []any{[]any{5, 1}, []any{5, 2}}}, tier: 1, used: 1, wantErr: true},
+		{name: "unknown op", value: spec("approx"), tier: 1, used: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "used", Op: OperatorCurve, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"tier": tt.tier, "used": tt.used})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// dateLayouts are the string layouts recognised as dates, tried in order.
+var dateLayouts = []string{time.RFC3339Nano, time.DateOnly}
+
+// toTime converts time.Time values and RFC3339 (or YYYY-MM-DD) strings to a
+// time.Time.
+func toTime(v any) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, true
+	case *time.Time:
+		if x != nil {
+			return *x, true
+		}
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// OperatorBusinessDay matches a date field falling on a weekday that is not
+// one of the engine's Holidays, judged in the engine's Location. The
+// condition value is ignored.
+const OperatorBusinessDay Operator = "business_day"
+
+func (e *Engine) businessDay(a, _ any) (bool, error) {
+	t, ok := toTime(a)
+	if !ok {
+		return false, fmt.Errorf("business_day requires a date, got %v", a)
+	}
+	t = t.In(e.location())
+	if wd := t.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false, nil
+	}
+	y, m, d := t.Date()
+	for _, h := range e.Holidays {
+		hy, hm, hd := h.Date()
+		if hy == y && hm == m && hd == d {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// location returns the engine's Location, defaulting to UTC.
+func (e *Engine) location() *time.Location {
+	if e.Location == nil {
+		return time.UTC
+	}
+	return e.Location
+}
+
+// NowRef is a condition value resolved to the engine's current time, e.g.
+// {"field":"expires_at","op":"lt","value":"$now"}.
+const NowRef = "$now"
+
+// now reads the engine's clock.
+func (e *Engine) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// OperatorAgeGT and OperatorAgeLT compare the time elapsed from a date field
+// to now against a duration such as "2y", "18mo", "2w3d" or "36h".
+// Besides the units of time.ParseDuration, whole numbers of years (y),
+// months (mo), weeks (w) and days (d) are accepted and applied on the
+// calendar, so "1y" is reached on the anniversary of the date. A field
+// exactly as old as the duration matches neither operator.
+const (
+	OperatorAgeGT Operator = "age_gt"
+	OperatorAgeLT Operator = "age_lt"
+)
+
+func (e *Engine) ageGT(a, b any) (bool, error) {
+	threshold, err := ageThreshold(OperatorAgeGT, a, b)
+	if err != nil {
+		return false, err
+	}
+	return e.now().After(threshold), nil
+}
+
+func (e *Engine) ageLT(a, b any) (bool, error) {
+	threshold, err := ageThreshold(OperatorAgeLT, a, b)
+	if err != nil {
+		return false, err
+	}
+	return e.now().Before(threshold), nil
+}
+
+// ageThreshold returns the instant at which date a reaches the age given by
+// duration b.
+func ageThreshold(op Operator, a, b any) (time.Time, error) {
+	t, ok := toTime(a)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s requires a date, got %v", op, a)
+	}
+	s, ok := b.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s requires a duration value", op)
+	}
+	years, months, days, d, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return t.AddDate(years, months, days).Add(d), nil
+}
+
+// parseAge splits a human duration into calendar years, months and days
+// plus a fixed duration.
+func parseAge(s string) (years, months, days int, d time.Duration, err error) {
+	if s == "" {
+		return 0, 0, 0, 0, fmt.Errorf("invalid duration %q", s)
+	}
+	for rest := s; rest != ""; {
+		i := 0
+		for i < len(rest) && (rest[i] >= '0' && rest[i] <= '9' || rest[i] == '.') {
+			i++
+		}
+		j := i
+		for j < len(rest) && (rest[j] < '0' || rest[j] > '9') && rest[j] != '.' {
+			j++
+		}
+		num, unit := rest[:i], rest[i:j]
+		rest = rest[j:]
+		if num == "" {
+			return 0, 0, 0, 0, fmt.Errorf("invalid duration %q", s)
+		}
+		switch unit {
+		case "y", "mo", "w", "d":
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, 0, 0, 0, fmt.Errorf("invalid duration %q: %s needs a whole number", s, unit)
+			}
+			switch unit {
+			case "y":
+				years += n
+			case "mo":
+				months += n
+			case "w":
+				days += 7 * n
+			case "d":
+				days += n
+			}
+		default:
+			part, err := time.ParseDuration(num + unit)
+			if err != nil {
+				return 0, 0, 0, 0, fmt.Errorf("invalid duration %q", s)
+			}
+			d += part
+		}
+	}
+	return years, months, days, d, nil
+}
+
+// OperatorWithinDuration matches when a date field lies within a duration of
+// the date in ValueField, in either direction:
+// {"field":"shipped_at","op":"within_duration","valueField":"ordered_at","value":"48h"}.
+// The value takes the same durations as OperatorAgeGT, such as "48h" or
+// "2d", and the window's bounds are inclusive.
+const OperatorWithinDuration Operator = "within_duration"
+
+func (e *Engine) withinDuration(_ context.Context, _ *evalState, c Condition, data map[string]any) (bool, error) {
+	if c.ValueField == "" {
+		return false, fmt.Errorf("within_duration requires valueField")
+	}
+	var times [2]time.Time
+	for i, path := range []string{c.Field, c.ValueField} {
+		v, err := e.field(data, path)
+		if err != nil {
+			return false, err
+		}
+		t, ok := toTime(v)
+		if !ok {
+			return false, fmt.Errorf("within_duration requires a date in field %q, got %v", e.fieldRef(path), v)
+		}
+		times[i] = t
+	}
+	s, ok := c.Value.(string)
+	if !ok {
+		return false, fmt.Errorf("within_duration requires a duration value")
+	}
+	years, months, days, d, err := parseAge(s)
+	if err != nil {
+		return false, fmt.Errorf("within_duration: %w", err)
+	}
+	earlier, later := times[0], times[1]
+	if later.Before(earlier) {
+		earlier, later = later, earlier
+	}
+	return !later.After(earlier.AddDate(years, months, days).Add(d)), nil
+}
@@ -0,0 +1,211 @@
+package rules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFieldReferenceDateOrdering(t *testing.T) {
+	tests := []struct {
+		name      string
+		submitted any
+		approved  any
+		op        Operator
+		want      bool
+		wantErr   bool
+	}{
+		{name: "approved after submitted", submitted: "2026-03-01T09:00:00Z", approved: "2026-03-02T10:00:00Z", op: OperatorGT, want: true},
+		{name: "approved before submitted", submitted: "2026-03-02T10:00:00Z", approved: "2026-03-01T09:00:00Z", op: OperatorGT, want: false},
+		{name: "offsets normalised", submitted: "2026-03-01T10:00:00+02:00", approved: "2026-03-01T09:00:00Z", op: OperatorGT, want: true},
+		{name: "same instant gte", submitted: "2026-03-01T10:00:00+01:00", approved: "2026-03-01T09:00:00Z", op: OperatorGTE, want: true},
+		{name: "time.Time values", submitted: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), approved: "2026-02-28", op: OperatorLT, want: true},
+		{name: "unparseable date", submitted: "2026-03-01T09:00:00Z", approved: "yesterday", op: OperatorGT, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "approved_at", Op: tt.op, Value: "$field:submitted_at"}}}
+			res, err := Evaluate(rule, map[string]any{"submitted_at": tt.submitted, "approved_at": tt.approved})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldReferenceMissing(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "approved_at", Op: OperatorGT, Value: "$field:submitted_at"}}}
+	if _, err := Evaluate(rule, map[string]any{"approved_at": "2026-03-02T10:00:00Z"}); err == nil {
+		t.Error("expected error for missing referenced field")
+	}
+}
+
+func TestBusinessDay(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable:", err)
+	}
+	e := New()
+	e.Location = ny
+	e.Holidays = []time.Time{time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name    string
+		due     any
+		want    bool
+		wantErr bool
+	}{
+		{name: "weekday", due: "2026-12-22T15:00:00Z", want: true},
+		{name: "saturday", due: "2026-12-26T15:00:00Z", want: false},
+		{name: "sunday", due: "2026-12-27", want: false},
+		{name: "holiday", due: "2026-12-25T15:00:00Z", want: false},
+		{name: "monday in utc is sunday in new york", due: "2026-12-21T03:00:00Z", want: false},
+		{name: "holiday eve late in new york", due: "2026-12-25T03:00:00Z", want: true},
+		{name: "time value", due: time.Date(2026, time.December, 23, 12, 0, 0, 0, ny), want: true},
+		{name: "not a date", due: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "due_date", Op: OperatorBusinessDay}}}
+			res, err := e.Evaluate(rule, map[string]any{"due_date": tt.due})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	e := New()
+	e.Now = func() time.Time { return now }
+	tests := []struct {
+		name    string
+		created any
+		op      Operator
+		age     any
+		want    bool
+		wantErr bool
+	}{
+		{name: "older than 2y", created: "2024-03-15T11:59:59Z", op: OperatorAgeGT, age: "2y", want: true},
+		{name: "exactly 2y is not older", created: "2024-03-15T12:00:00Z", op: OperatorAgeGT, age: "2y", want: false},
+		{name: "exactly 2y is not younger", created: "2024-03-15T12:00:00Z", op: OperatorAgeLT, age: "2y", want: false},
+		{name: "younger than 2y", created: "2024-03-15T12:00:01Z", op: OperatorAgeLT, age: "2y", want: true},
+		{name: "months on the calendar", created: "2026-01-15", op: OperatorAgeGT, age: "2mo", want: true},
+		{name: "weeks and days", created: "2026-03-05T12:00:00Z", op: OperatorAgeLT, age: "1w3d1s", want: true},
+		{name: "go duration units", created: "2026-03-14T11:00:00Z", op: OperatorAgeGT, age: "24h30m", want: true},
+		{name: "time.Time field", created: now.Add(-time.Hour), op: OperatorAgeLT, age: "2h", want: true},
+		{name: "fractional days", created: "2026-03-01", op: OperatorAgeGT, age: "1.5d", wantErr: true},
+		{name: "unknown unit", created: "2026-03-01", op: OperatorAgeGT, age: "2 years", wantErr: true},
+		{name: "missing number", created: "2026-03-01", op: OperatorAgeGT, age: "y", wantErr: true},
+		{name: "non-string duration", created: "2026-03-01", op: OperatorAgeGT, age: 2, wantErr: true},
+		{name: "not a date", created: "last year", op: OperatorAgeGT, age: "1y", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "created_at", Op: tt.op, Value: tt.age}}}
+			res, err := e.Evaluate(rule, map[string]any{"created_at": tt.created})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestNowRef(t *testing.T) {
+	e := New()
+	e.Now = func() time.Time { return time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC) }
+	rule := Rule{Conditions: []Condition{{Field: "expires_at", Op: OperatorLT, Value: NowRef}}}
+	for expires, want := range map[string]bool{"2026-03-14": true, "2026-03-16": false} {
+		res, err := e.Evaluate(rule, map[string]any{"expires_at": expires})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != want {
+			t.Errorf("expires_at %s: Matched = %v, want %v", expires, res.Matched, want)
+		}
+	}
+}
+
+func TestWithinDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		shipped any
+		ordered any
+		window  any
+		want    bool
+		wantErr bool
+	}{
+		{name: "within window", shipped: "2026-03-02T10:00:00Z", ordered: "2026-03-01T09:00:00Z", window: "48h", want: true},
+		{name: "on boundary", shipped: "2026-03-03T09:00:00Z", ordered: "2026-03-01T09:00:00Z", window: "48h", want: true},
+		{name: "outside window", shipped: "2026-03-03T09:00:01Z", ordered: "2026-03-01T09:00:00Z", window: "48h", want: false},
+		{name: "either direction", shipped: "2026-03-01T09:00:00Z", ordered: "2026-03-02T09:00:00Z", window: "1d", want: true},
+		{name: "offsets normalised", shipped: "2026-03-01T12:00:00+02:00", ordered: "2026-03-01T09:00:00Z", window: "1h", want: true},
+		{name: "calendar units", shipped: "2026-03-31", ordered: "2026-03-01", window: "1mo", want: true},
+		{name: "unparseable shipped", shipped: "soon", ordered: "2026-03-01T09:00:00Z", window: "48h", wantErr: true},
+		{name: "unparseable ordered", shipped: "2026-03-01T09:00:00Z", ordered: 7, window: "48h", wantErr: true},
+		{name: "invalid window", shipped: "2026-03-01", ordered: "2026-03-01", window: "two days", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "shipped_at", Op: OperatorWithinDuration, ValueField: "ordered_at", Value: tt.window}}}
+			res, err := Evaluate(rule, map[string]any{"shipped_at": tt.shipped, "ordered_at": tt.ordered})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateEquality(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	tests := []struct {
+		name  string
+		field any
+		op    Operator
+		value any
+		want  bool
+	}{
+		{name: "same instant different offsets", field: "2024-01-01T09:00:00+09:00", op: OperatorEQ, value: "2024-01-01T00:00:00Z", want: true},
+		{name: "same instant not ne", field: "2024-01-01T09:00:00+09:00", op: OperatorNE, value: "2024-01-01T00:00:00Z", want: false},
+		{name: "time.Time in another zone", field: time.Date(2024, 1, 1, 9, 0, 0, 0, tokyo), op: OperatorEQ, value: "2024-01-01T00:00:00Z", want: true},
+		{name: "different instants same wall clock", field: "2024-01-01T00:00:00+09:00", op: OperatorEQ, value: "2024-01-01T00:00:00Z", want: false},
+		{name: "date after", field: "2024-06-01T00:00:00Z", op: OperatorGT, value: "2024-01-01T00:00:00Z", want: true},
+		{name: "date before", field: "2023-12-31T23:59:59Z", op: OperatorLT, value: "2024-01-01T00:00:00Z", want: true},
+		{name: "in list of dates", field: "2024-01-01T01:00:00+01:00", op: OperatorIn, value: []any{"2024-01-01T00:00:00Z"}, want: true},
+		{name: "numeric strings stay numbers", field: "20240101", op: OperatorEQ, value: "2024-01-01", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(Rule{Conditions: []Condition{{Field: "created_at", Op: tt.op, Value: tt.value}}}, map[string]any{"created_at": tt.field})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateComparisonCaseInsensitive(t *testing.T) {
+	e := NewWithOptions(Options{CaseInsensitive: true})
+	rule := Rule{Conditions: []Condition{{Field: "created_at", Op: OperatorGT, Value: "2024-01-01T00:00:00Z"}}}
+	res, err := e.Evaluate(rule, map[string]any{"created_at": "2024-02-01T00:00:00Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Errorf("expected match: %s", res.Explanation)
+	}
+}
@@ -0,0 +1,244 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Namespaces under which EvaluateDelta exposes the two sides of a change, so
+// conditions can reference "$before.amount" and "$after.amount". The delta
+// operators look these paths up as any other field, through the Resolver,
+// virtual fields and FallbackPrefix.
+const (
+	BeforeKey = "$before"
+	AfterKey  = "$after"
+)
+
+// OperatorChangedByAtLeast matches when a numeric field changed by at least
+// the condition value between $before and $after, in either direction. The
+// condition's Field is the unprefixed path, e.g. "amount". A field missing
+// from either side does not match.
+const OperatorChangedByAtLeast Operator = "changed_by_at_least"
+
+// OperatorAdded and OperatorRemoved count the elements added to or removed
+// from a slice field between $before and $after, compared element-wise with
+// the engine's eq, so CaseInsensitive, StrictTypes and eq tolerances apply,
+// and respecting multiplicity. The condition value says how
+// the count is compared, e.g. {"op":"gt","value":0}. A side missing the field
+// counts as an empty slice.
+const (
+	OperatorAdded   Operator = "added"
+	OperatorRemoved Operator = "removed"
+)
+
+// OperatorTransition matches when a field's change from $before to $after is
+// allowed by the transition table registered under the condition value's
+// name (see RegisterTransitions). A field missing from either side does not
+// match.
+const OperatorTransition Operator = "transition"
+
+// RegisterTransitions registers a state machine transition table: table maps
+// each state to the states it may move to.
+func (e *Engine) RegisterTransitions(name string, table map[string][]string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.transitions[name] = table
+}
+
+// EvaluateDelta evaluates rule against a change from before to after, with
+// the two maps available under BeforeKey and AfterKey.
+func (e *Engine) EvaluateDelta(rule Rule, before, after map[string]any) (Result, error) {
+	return e.Evaluate(rule, map[string]any{BeforeKey: before, AfterKey: after})
+}
+
+func (e *Engine) changedByAtLeast(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	before, after, ok, err := e.deltaValues(st, c, data)
+	if err != nil || !ok {
+		return false, err
+	}
+	fb, okb := toFloat(before)
+	fa, oka := toFloat(after)
+	if !okb || !oka {
+		return false, fmt.Errorf("type mismatch for changed_by_at_least")
+	}
+	want, err := e.conditionValue(ctx, st, c, data)
+	if err != nil {
+		return false, err
+	}
+	delta, ok := toFloat(want)
+	if !ok {
+		return false, fmt.Errorf("changed_by_at_least requires numeric value")
+	}
+	return math.Abs(fa-fb) >= delta, nil
+}
+
+func (e *Engine) added(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	before, after, err := e.deltaSlices(st, c, data)
+	if err != nil {
+		return false, err
+	}
+	diff, err := difference(after, before, e.equalsFunc(ctx, st, data))
+	if err != nil {
+		return false, err
+	}
+	return e.applySpec(ctx, st, c.Op, c.Value, len(diff), data)
+}
+
+func (e *Engine) removed(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	before, after, err := e.deltaSlices(st, c, data)
+	if err != nil {
+		return false, err
+	}
+	diff, err := difference(before, after, e.equalsFunc(ctx, st, data))
+	if err != nil {
+		return false, err
+	}
+	return e.applySpec(ctx, st, c.Op, c.Value, len(diff), data)
+}
+
+// equalsFunc returns the engine's eq operator for comparing slice elements,
+// so that its options and tolerance apply.
+func (e *Engine) equalsFunc(ctx context.Context, st *evalState, data map[string]any) func(x, y any) (bool, error) {
+	return func(x, y any) (bool, error) {
+		return e.apply(ctx, st, OperatorEQ, x, y, data)
+	}
+}
+
+// deltaValues looks up the $before and $after values of c's field, as any
+// other field is looked up; ok is false when either side is missing.
+func (e *Engine) deltaValues(st *evalState, c Condition, data map[string]any) (before, after any, ok bool, err error) {
+	before, okBefore, err := e.deltaValue(st, BeforeKey, c.Field, data)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	after, okAfter, err := e.deltaValue(st, AfterKey, c.Field, data)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return before, after, okBefore && okAfter, nil
+}
+
+// deltaValue looks up field on the side of a change under key.
+func (e *Engine) deltaValue(st *evalState, key, field string, data map[string]any) (any, bool, error) {
+	path := key + "." + field
+	v, ok, err := e.lookup(data, path)
+	if ok {
+		st.input(path, v)
+	}
+	return v, ok, err
+}
+
+// deltaSlices returns the $before and $after values of a slice field.
+func (e *Engine) deltaSlices(st *evalState, c Condition, data map[string]any) (before, after []any, err error) {
+	for _, side := range []struct {
+		key string
+		dst *[]any
+	}{{BeforeKey, &before}, {AfterKey, &after}} {
+		v, ok, err := e.deltaValue(st, side.key, c.Field, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		items, ok := v.([]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("type mismatch for %s", c.Op)
+		}
+		*side.dst = items
+	}
+	return before, after, nil
+}
+
+// difference returns the elements of a not matched by an element of b under
+// eq, each element of b matching at most once.
+func difference(a, b []any, eq func(x, y any) (bool, error)) ([]any, error) {
+	used := make([]bool, len(b))
+	var out []any
+	for _, x := range a {
+		found := false
+		for i, y := range b {
+			if used[i] {
+				continue
+			}
+			same, err := eq(x, y)
+			if err != nil {
+				return nil, err
+			}
+			if same {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			out = append(out, x)
+		}
+	}
+	return out, nil
+}
+
+func (e *Engine) transition(_ context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	name, ok := c.Value.(string)
+	if !ok {
+		return false, fmt.Errorf("transition requires a transition table name value")
+	}
+	e.mu.RLock()
+	table, ok := e.transitions[name]
+	e.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("unknown transition table %q", name)
+	}
+	before, after, ok, err := e.deltaValues(st, c, data)
+	if err != nil || !ok {
+		return false, err
+	}
+	from, okFrom := before.(string)
+	to, okTo := after.(string)
+	if !okFrom || !okTo {
+		return false, fmt.Errorf("type mismatch for transition")
+	}
+	return slices.Contains(table[from], to), nil
+}
+
+// ConditionChange is a condition whose outcome differs between two data maps.
+// Index is its position in the rule's Conditions or, for a rule with a Root
+// group, among the group's conditions in depth-first order.
+type ConditionChange struct {
+	Index     int       `json:"index"`
+	Condition Condition `json:"condition"`
+	Before    bool      `json:"before"`
+	After     bool      `json:"after"`
+}
+
+// ConditionDiff lists the conditions of rule whose outcome differs between
+// before and after, to show why a decision changed. Each condition is
+// evaluated on its own, without short-circuiting and ignoring When, so
+// every flip is reported. A field missing from either side is an error
+// unless the engine treats missing fields as non-matches.
+func (e *Engine) ConditionDiff(rule Rule, before, after map[string]any) ([]ConditionChange, error) {
+	ctx := context.Background()
+	conds := rule.Conditions
+	if rule.Root != nil {
+		conds = rule.Root.conditions(nil)
+	}
+	var changes []ConditionChange
+	for i, c := range conds {
+		var outcomes [2]bool
+		for j, side := range []struct {
+			name string
+			data map[string]any
+		}{{"before", before}, {"after", after}} {
+			matched, _, err := e.evalCondition(ctx, &evalState{}, c, side.data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: condition %d: %w", side.name, i, err)
+			}
+			outcomes[j] = matched
+		}
+		if outcomes[0] != outcomes[1] {
+			changes = append(changes, ConditionChange{Index: i, Condition: c, Before: outcomes[0], After: outcomes[1]})
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateDelta(t *testing.T) {
+	rule := Rule{Conditions: []Condition{
+		{Field: "$after.status", Op: OperatorEQ, Value: "active"},
+		{Field: "$before.status", Op: OperatorNE, Value: "active"},
+	}}
+	res, err := New().EvaluateDelta(rule, map[string]any{"status": "trial"}, map[string]any{"status": "active"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected status change to match")
+	}
+}
+
+func TestChangedByAtLeast(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "balance", Op: OperatorChangedByAtLeast, Value: 100}}}
+	tests := []struct {
+		name    string
+		before  map[string]any
+		after   map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{name: "increase above delta", before: map[string]any{"balance": 500}, after: map[string]any{"balance": 650}, want: true},
+		{name: "decrease above delta", before: map[string]any{"balance": 500}, after: map[string]any{"balance": 350}, want: true},
+		{name: "exactly delta", before: map[string]any{"balance": 500}, after: map[string]any{"balance": 600}, want: true},
+		{name: "below delta", before: map[string]any{"balance": 500}, after: map[string]any{"balance": 550}, want: false},
+		{name: "missing before", before: map[string]any{}, after: map[string]any{"balance": 550}, want: false},
+		{name: "missing after", before: map[string]any{"balance": 500}, after: nil, want: false},
+		{name: "non-numeric", before: map[string]any{"balance": "lots"}, after: map[string]any{"balance": 550}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := New().EvaluateDelta(rule, tt.before, tt.after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddedRemoved(t *testing.T) {
+	addedAny := Rule{Conditions: []Condition{{Field: "items", Op: OperatorAdded, Value: map[string]any{"op": "gt", "value": 0}}}}
+	removedTwo := Rule{Conditions: []Condition{{Field: "items", Op: OperatorRemoved, Value: map[string]any{"op": "gte", "value": 2}}}}
+	tests := []struct {
+		name        string
+		before      map[string]any
+		after       map[string]any
+		wantAdded   bool
+		wantRemoved bool
+	}{
+		{name: "addition", before: map[string]any{"items": []any{"a"}}, after: map[string]any{"items": []any{"a", "b"}}, wantAdded: true},
+		{name: "removals", before: map[string]any{"items": []any{"a", "b", "c"}}, after: map[string]any{"items": []any{"b"}}, wantRemoved: true},
+		{name: "no change in different order", before: map[string]any{"items": []any{"a", "b"}}, after: map[string]any{"items": []any{"b", "a"}}},
+		{name: "duplicate added", before: map[string]any{"items": []any{"a"}}, after: map[string]any{"items": []any{"a", "a"}}, wantAdded: true},
+		{name: "missing before counts as empty", before: map[string]any{}, after: map[string]any{"items": []any{1}}, wantAdded: true},
+		{name: "both replaced", before: map[string]any{"items": []any{1, 2}}, after: map[string]any{"items": []any{3}}, wantAdded: true, wantRemoved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := New().EvaluateDelta(addedAny, tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.wantAdded {
+				t.Errorf("added: Matched = %v, want %v", res.Matched, tt.wantAdded)
+			}
+			res, err = New().EvaluateDelta(removedTwo, tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.wantRemoved {
+				t.Errorf("removed: Matched = %v, want %v", res.Matched, tt.wantRemoved)
+			}
+		})
+	}
+
+	bad := []struct {
+		name   string
+		rule   Rule
+		before map[string]any
+	}{
+		{name: "non-slice field", rule: addedAny, before: map[string]any{"items": "a"}},
+		{name: "malformed comparison", rule: Rule{Conditions: []Condition{{Field: "items", Op: OperatorAdded, Value: 1}}}, before: map[string]any{"items": []any{}}},
+	}
+	for _, tt := range bad {
+		if _, err := New().EvaluateDelta(tt.rule, tt.before, map[string]any{"items": []any{}}); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestDeltaOperatorsUseEngine(t *testing.T) {
+	tags := Condition{Field: "tags", Op: OperatorAdded, Value: map[string]any{"op": "gt", "value": 0}}
+	resolver := FieldResolverFunc(func(path string) (any, bool, error) {
+		return 150, path == "$after.amount", nil
+	})
+	tests := []struct {
+		name    string
+		engine  func() *Engine
+		cond    Condition
+		before  map[string]any
+		after   map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "case-insensitive elements",
+			engine: func() *Engine { return NewWithOptions(Options{CaseInsensitive: true}) },
+			cond:   tags,
+			before: map[string]any{"tags": []any{"VIP"}},
+			after:  map[string]any{"tags": []any{"vip"}},
+			want:   false,
+		},
+		{
+			name:   "strict element types",
+			engine: func() *Engine { return NewWithOptions(Options{StrictTypes: true}) },
+			cond:   tags,
+			before: map[string]any{"tags": []any{1}},
+			after:  map[string]any{"tags": []any{"1"}},
+			want:   true,
+		},
+		{
+			name: "resolved side",
+			engine: func() *Engine {
+				e := New()
+				e.Resolver = resolver
+				return e
+			},
+			cond:   Condition{Field: "amount", Op: OperatorChangedByAtLeast, Value: 50},
+			before: map[string]any{"amount": 100},
+			after:  map[string]any{},
+			want:   true,
+		},
+		{
+			name: "resolver error",
+			engine: func() *Engine {
+				e := New()
+				e.Resolver = FieldResolverFunc(func(string) (any, bool, error) { return nil, false, errors.New("down") })
+				return e
+			},
+			cond:    Condition{Field: "status", Op: OperatorTransition, Value: "orders"},
+			before:  map[string]any{"status": "new"},
+			after:   map[string]any{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.engine()
+			e.RegisterTransitions("orders", map[string][]string{"new": {"paid"}})
+			res, err := e.EvaluateDelta(Rule{Conditions: []Condition{tt.cond}}, tt.before, tt.after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransition(t *testing.T) {
+	e := New()
+	e.RegisterTransitions("order", map[string][]string{
+		"pending": {"paid", "cancelled"},
+		"paid":    {"shipped", "refunded"},
+		"shipped": {"delivered"},
+	})
+	rule := Rule{Conditions: []Condition{{Field: "status", Op: OperatorTransition, Value: "order"}}}
+	tests := []struct {
+		from, to any
+		want     bool
+		wantErr  bool
+	}{
+		{from: "pending", to: "paid", want: true},
+		{from: "paid", to: "shipped", want: true},
+		{from: "pending", to: "shipped", want: false},
+		{from: "delivered", to: "pending", want: false},
+		{from: "paid", to: "paid", want: false},
+		{from: "paid", to: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		res, err := e.EvaluateDelta(rule, map[string]any{"status": tt.from}, map[string]any{"status": tt.to})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%v→%v: error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+		if res.Matched != tt.want {
+			t.Errorf("%v→%v: Matched = %v, want %v", tt.from, tt.to, res.Matched, tt.want)
+		}
+	}
+
+	unknown := Rule{Conditions: []Condition{{Field: "status", Op: OperatorTransition, Value: "invoice"}}}
+	if _, err := e.EvaluateDelta(unknown, map[string]any{"status": "pending"}, map[string]any{"status": "paid"}); err == nil {
+		t.Error("expected error for unknown transition table")
+	}
+}
+
+func TestConditionDiff(t *testing.T) {
+	age := Condition{Field: "age", Op: OperatorGTE, Value: 18}
+	country := Condition{Field: "country", Op: OperatorIn, Value: []any{"US", "CA"}}
+	score := Condition{Field: "score", Op: OperatorGT, Value: 700}
+	before := map[string]any{"age": 30, "country": "US", "score": 720}
+	after := map[string]any{"age": 30, "country": "MX", "score": 720}
+	tests := []struct {
+		name string
+		rule Rule
+		want []ConditionChange
+	}{
+		{
+			name: "one condition flips",
+			rule: Rule{Conditions: []Condition{age, country, score}},
+			want: []ConditionChange{{Index: 1, Condition: country, Before: true, After: false}},
+		},
+		{
+			name: "conditions after a failure are still compared",
+			rule: Rule{Conditions: []Condition{{Field: "age", Op: OperatorLT, Value: 18}, country}},
+			want: []ConditionChange{{Index: 1, Condition: country, Before: true, After: false}},
+		},
+		{
+			name: "negated condition",
+			rule: Rule{Conditions: []Condition{{Field: "country", Op: OperatorEQ, Value: "MX", Negate: true}}},
+			want: []ConditionChange{{Index: 0, Condition: Condition{Field: "country", Op: OperatorEQ, Value: "MX", Negate: true}, Before: true, After: false}},
+		},
+		{
+			name: "grouped rule",
+			rule: Rule{Root: &Group{Items: []Item{
+				{Condition: &age},
+				{Group: &Group{Logic: LogicOR, Items: []Item{{Condition: &score}, {Condition: &country}}}},
+			}}},
+			want: []ConditionChange{{Index: 2, Condition: country, Before: true, After: false}},
+		},
+		{
+			name: "nothing flips",
+			rule: Rule{Conditions: []Condition{age, score}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().ConditionDiff(tt.rule, before, after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing field", func(t *testing.T) {
+		rule := Rule{Conditions: []Condition{{Field: "beta", Op: OperatorEQ, Value: true}}}
+		if _, err := New().ConditionDiff(rule, map[string]any{}, map[string]any{"beta": true}); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("error = %v, want ErrFieldNotFound", err)
+		}
+		e := NewWithOptions(Options{MissingFieldBehavior: MissingAsNoMatch})
+		got, err := e.ConditionDiff(rule, map[string]any{}, map[string]any{"beta": true})
+		if err != nil || len(got) != 1 || got[0].Before || !got[0].After {
+			t.Errorf("got %+v, %v; want a false to true change", got, err)
+		}
+	})
+}
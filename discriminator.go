@@ -0,0 +1,34 @@
+package rules
+
+import "fmt"
+
+// FieldSelector picks a condition's field path by the value of a
+// discriminator field, e.g.
+//
+//	{"discriminator":"type","paths":{"card":"payment.card.country","bank":"payment.bank.country"}}
+//
+// reads the country from a different place for each payment type. Default,
+// when set, is used for discriminator values without a path; otherwise
+// such values are an error. Non-string discriminators are matched in their
+// string form.
+type FieldSelector struct {
+	Discriminator string            `json:"discriminator"`
+	Paths         map[string]string `json:"paths"`
+	Default       string            `json:"default,omitempty"`
+}
+
+// resolveFieldBy returns the field path sel selects for data.
+func (e *Engine) resolveFieldBy(sel *FieldSelector, data map[string]any) (string, error) {
+	v, err := e.field(data, sel.Discriminator)
+	if err != nil {
+		return "", err
+	}
+	key := fmt.Sprint(v)
+	if path, ok := sel.Paths[key]; ok {
+		return path, nil
+	}
+	if sel.Default != "" {
+		return sel.Default, nil
+	}
+	return "", fmt.Errorf("no field path for discriminator %q value %q", sel.Discriminator, key)
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFieldBy(t *testing.T) {
+	sel := &FieldSelector{
+		Discriminator: "type",
+		Paths: map[string]string{
+			"card": "payment.card.country",
+			"bank": "payment.bank.country",
+		},
+	}
+	rule := Rule{Conditions: []Condition{{FieldBy: sel, Op: OperatorEQ, Value: "DE"}}}
+	tests := []struct {
+		name     string
+		data     map[string]any
+		want     bool
+		wantExpl string
+		wantErr  bool
+	}{
+		{
+			name:     "card path",
+			data:     map[string]any{"type": "card", "payment": map[string]any{"card": map[string]any{"country": "DE"}}},
+			want:     true,
+			wantExpl: "all conditions met",
+		},
+		{
+			name:     "bank path",
+			data:     map[string]any{"type": "bank", "payment": map[string]any{"bank": map[string]any{"country": "FR"}}},
+			wantExpl: "payment.bank.country eq DE → false",
+		},
+		{
+			name:    "path not followed for other type",
+			data:    map[string]any{"type": "bank", "payment": map[string]any{"card": map[string]any{"country": "DE"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unmapped discriminator",
+			data:    map[string]any{"type": "crypto"},
+			wantErr: true,
+		},
+		{
+			name:    "missing discriminator",
+			data:    map[string]any{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(rule, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want || res.Explanation != tt.wantExpl {
+				t.Errorf("got %v %q, want %v %q", res.Matched, res.Explanation, tt.want, tt.wantExpl)
+			}
+		})
+	}
+
+	t.Run("missing discriminator is ErrFieldNotFound", func(t *testing.T) {
+		if _, err := Evaluate(rule, map[string]any{}); !errors.Is(err, ErrFieldNotFound) {
+			t.Errorf("error = %v, want ErrFieldNotFound", err)
+		}
+	})
+	t.Run("default path", func(t *testing.T) {
+		withDefault := *sel
+		withDefault.Default = "country"
+		rule := Rule{Conditions: []Condition{{FieldBy: &withDefault, Op: OperatorEQ, Value: "DE"}}}
+		res, err := Evaluate(rule, map[string]any{"type": "crypto", "country": "DE"})
+		if err != nil || !res.Matched {
+			t.Errorf("got %v, %v; want match", res.Matched, err)
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		var rule Rule
+		src := `{"conditions":[{"fieldBy":{"discriminator":"kind","paths":{"1":"a","2":"b"}},"op":"gt","value":5}]}`
+		if err := json.Unmarshal([]byte(src), &rule); err != nil {
+			t.Fatal(err)
+		}
+		res, err := Evaluate(rule, map[string]any{"kind": 2, "a": 1, "b": 9})
+		if err != nil || !res.Matched {
+			t.Errorf("got %v, %v; want match on b", res.Matched, err)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package rules
+
+import "fmt"
+
+// OperatorInEnum matches a string field against an enum registered with
+// RegisterEnum; the condition value is the enum's name.
+const OperatorInEnum Operator = "inenum"
+
+// RegisterEnum registers a named set of allowed string values for use with
+// OperatorInEnum, replacing any set previously registered under name.
+func (e *Engine) RegisterEnum(name string, values []string) {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enums[name] = set
+}
+
+func (e *Engine) inEnum(a, b any) (bool, error) {
+	name, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("inenum requires an enum name value")
+	}
+	e.mu.RLock()
+	set, ok := e.enums[name]
+	e.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("unknown enum %q", name)
+	}
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for inenum")
+	}
+	_, ok = set[s]
+	return ok, nil
+}
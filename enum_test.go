@@ -0,0 +1,34 @@
+package rules
+
+import "testing"
+
+func TestInEnum(t *testing.T) {
+	e := New()
+	e.RegisterEnum("plan", []string{"free", "pro", "enterprise"})
+	tests := []struct {
+		name    string
+		value   any
+		enum    any
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid value", value: "pro", enum: "plan", want: true},
+		{name: "invalid value", value: "platinum", enum: "plan", want: false},
+		{name: "case sensitive", value: "Pro", enum: "plan", want: false},
+		{name: "unknown enum", value: "pro", enum: "tier", wantErr: true},
+		{name: "non-string field", value: 1, enum: "plan", wantErr: true},
+		{name: "non-string enum name", value: "pro", enum: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "plan", Op: OperatorInEnum, Value: tt.enum}}}
+			res, err := e.Evaluate(rule, map[string]any{"plan": tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
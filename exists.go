@@ -0,0 +1,26 @@
+package rules
+
+import "context"
+
+// OperatorExists and OperatorNotExists test whether a field is present in
+// the data, e.g. {"field":"coupon","op":"exists"}, instead of comparing its
+// value. A missing field is not an error for them, and a field present with
+// a null value exists. The condition value is ignored.
+const (
+	OperatorExists    Operator = "exists"
+	OperatorNotExists Operator = "notexists"
+)
+
+func (e *Engine) exists(_ context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	v, ok, err := e.lookup(data, c.Field)
+	if ok {
+		st.input(c.Field, v)
+		st.resolved(v, c.Value)
+	}
+	return ok, err
+}
+
+func (e *Engine) notExists(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	ok, err := e.exists(ctx, st, c, data)
+	return !ok && err == nil, err
+}
@@ -0,0 +1,49 @@
+package rules
+
+import "testing"
+
+func TestExists(t *testing.T) {
+	data := map[string]any{
+		"coupon": "SAVE10",
+		"note":   nil,
+		"cart":   map[string]any{"items": []any{map[string]any{"sku": "A1"}}},
+	}
+	tests := []struct {
+		name  string
+		field string
+		op    Operator
+		want  bool
+	}{
+		{name: "present", field: "coupon", op: OperatorExists, want: true},
+		{name: "absent", field: "referrer", op: OperatorExists, want: false},
+		{name: "null valued", field: "note", op: OperatorExists, want: true},
+		{name: "nested present", field: "cart.items.0.sku", op: OperatorExists, want: true},
+		{name: "nested absent", field: "cart.items.1.sku", op: OperatorExists, want: false},
+		{name: "not exists present", field: "coupon", op: OperatorNotExists, want: false},
+		{name: "not exists absent", field: "referrer", op: OperatorNotExists, want: true},
+		{name: "not exists null valued", field: "note", op: OperatorNotExists, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(Rule{Conditions: []Condition{{Field: tt.field, Op: tt.op}}}, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestExistsVirtualField(t *testing.T) {
+	e := New()
+	e.RegisterVirtual("full_name", func(data map[string]any) (any, error) { return "Ada Lovelace", nil })
+	res, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "full_name", Op: OperatorExists}}}, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("virtual fields should exist")
+	}
+}
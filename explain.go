@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConditionResult is the Result.Trace entry for one evaluated condition.
+// Actual is the field's value, after any transforms and unit normalisation,
+// and Expected the resolved comparison value, such as the value of the
+// ValueField. Matched is the condition's outcome, after negation. Missing
+// reports a field missing under MissingAsNoMatch, leaving Actual nil.
+type ConditionResult struct {
+	Field    string   `json:"field"`
+	Op       Operator `json:"op"`
+	Expected any      `json:"expected"`
+	Actual   any      `json:"actual"`
+	Negated  bool     `json:"negated,omitempty"`
+	Missing  bool     `json:"missing,omitempty"`
+	Matched  bool     `json:"matched"`
+}
+
+// MinimalFailure returns the conditions that would have to change for a
+// failing rule to match. Under AND logic that is every failing condition;
+// under OR logic fixing any one condition suffices, so only the first is
+// returned. A failing NOT rule has every condition matching, and making any
+// one of them fail suffices, so the first is returned. Grouped rules apply
+// the same reasoning to each group of Root. Conditions referenced by a When
+// do not decide the rule and are never returned, and an implication whose
+// antecedent did not match holds. A matching rule yields nil.
+func (e *Engine) MinimalFailure(rule Rule, data map[string]any) ([]Condition, error) {
+	ctx := context.Background()
+	p, err := newPlan(rule)
+	if err != nil {
+		return nil, err
+	}
+	st := &evalState{}
+	if rule.Root != nil {
+		n, err := e.evalFailureGroup(ctx, st, rule.Root, data)
+		if err != nil {
+			return nil, err
+		}
+		return n.fixes(true, nil), nil
+	}
+	n := &failureNode{logic: p.logic}
+	results := make([]bool, len(rule.Conditions))
+	for i := range rule.Conditions {
+		matched, _, err := e.evalImplication(ctx, st, rule.Conditions[i], results, data)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = matched
+		if p.antecedents[i] {
+			continue
+		}
+		n.children = append(n.children, &failureNode{cond: &rule.Conditions[i], matched: matched})
+	}
+	n.combine()
+	return n.fixes(true, nil), nil
+}
+
+// failureNode is a condition, or a rule or group with its logic, evaluated
+// in full for MinimalFailure.
+type failureNode struct {
+	cond     *Condition
+	logic    Logic
+	matched  bool
+	children []*failureNode
+}
+
+// combine sets n's outcome from its children's.
+func (n *failureNode) combine() {
+	allMatched, anyMatched := true, false
+	for _, c := range n.children {
+		allMatched = allMatched && c.matched
+		anyMatched = anyMatched || c.matched
+	}
+	switch n.logic {
+	case LogicOR:
+		n.matched = anyMatched
+	case LogicNOT:
+		n.matched = !allMatched
+	default:
+		n.matched = allMatched
+	}
+}
+
+// fixes appends to dst the conditions that would have to change for n to
+// evaluate to target. AND and NOT combine their children with "all", OR
+// with "any": making "all" true or "any" false needs every dissenting child
+// changed, while making "all" false or "any" true needs only the first.
+func (n *failureNode) fixes(target bool, dst []Condition) []Condition {
+	if n.matched == target {
+		return dst
+	}
+	if n.cond != nil {
+		return append(dst, *n.cond)
+	}
+	all := n.logic != LogicOR
+	want := target != (n.logic == LogicNOT)
+	for _, c := range n.children {
+		if c.matched == want {
+			continue
+		}
+		dst = c.fixes(want, dst)
+		if all != want {
+			break
+		}
+	}
+	return dst
+}
+
+// evalFailureGroup evaluates every item of g for MinimalFailure.
+func (e *Engine) evalFailureGroup(ctx context.Context, st *evalState, g *Group, data map[string]any) (*failureNode, error) {
+	n := &failureNode{logic: g.Logic}
+	for i, item := range g.Items {
+		var child *failureNode
+		switch {
+		case item.Condition != nil && item.Group == nil:
+			if item.Condition.When != "" {
+				return nil, fmt.Errorf("group item %d: when is not supported in groups", i)
+			}
+			matched, _, err := e.evalCondition(ctx, st, *item.Condition, data)
+			if err != nil {
+				return nil, err
+			}
+			child = &failureNode{cond: item.Condition, matched: matched}
+		case item.Group != nil && item.Condition == nil:
+			var err error
+			if child, err = e.evalFailureGroup(ctx, st, item.Group, data); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("group item %d must set exactly one of condition or group", i)
+		}
+		n.children = append(n.children, child)
+	}
+	n.combine()
+	return n, nil
+}
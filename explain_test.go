@@ -0,0 +1,272 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMinimalFailure(t *testing.T) {
+	age := Condition{Field: "age", Op: OperatorGTE, Value: 18}
+	premium := Condition{Field: "premium", Op: OperatorEQ, Value: true}
+	country := Condition{Field: "country", Op: OperatorIn, Value: []any{"US", "CA"}}
+	tests := []struct {
+		name string
+		rule Rule
+		data map[string]any
+		want []Condition
+	}{
+		{
+			name: "matching rule",
+			rule: Rule{Conditions: []Condition{age, premium, country}},
+			data: map[string]any{"age": 30, "premium": true, "country": "US"},
+			want: nil,
+		},
+		{
+			name: "single failure",
+			rule: Rule{Conditions: []Condition{age, premium, country}},
+			data: map[string]any{"age": 30, "premium": false, "country": "US"},
+			want: []Condition{premium},
+		},
+		{
+			name: "multiple failures",
+			rule: Rule{Conditions: []Condition{age, premium, country}},
+			data: map[string]any{"age": 16, "premium": true, "country": "FR"},
+			want: []Condition{age, country},
+		},
+		{
+			name: "or needs one fix",
+			rule: Rule{Conditions: []Condition{age, premium}, Logic: LogicOR},
+			data: map[string]any{"age": 16, "premium": false},
+			want: []Condition{age},
+		},
+		{
+			name: "not needs one condition to fail",
+			rule: Rule{Conditions: []Condition{age, premium}, Logic: LogicNOT},
+			data: map[string]any{"age": 30, "premium": true},
+			want: []Condition{age},
+		},
+		{
+			name: "group: and of or",
+			rule: Rule{Root: &Group{Items: []Item{
+				{Group: &Group{Logic: LogicOR, Items: []Item{{Condition: &age}, {Condition: &premium}}}},
+				{Condition: &country},
+			}}},
+			data: map[string]any{"age": 16, "premium": false, "country": "FR"},
+			want: []Condition{age, country},
+		},
+		{
+			name: "group: not",
+			rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+				{Group: &Group{Logic: LogicNOT, Items: []Item{{Condition: &age}, {Condition: &premium}}}},
+				{Condition: &country},
+			}}},
+			data: map[string]any{"age": 30, "premium": true, "country": "FR"},
+			want: []Condition{age},
+		},
+		{
+			name: "group: matching",
+			rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{{Condition: &age}, {Condition: &premium}}}},
+			data: map[string]any{"age": 16, "premium": true},
+			want: nil,
+		},
+		{
+			name: "implication with unmet antecedent",
+			rule: Rule{Conditions: []Condition{premium, {Field: "age", Op: OperatorGTE, Value: 21, When: "$cond:0"}, country}},
+			data: map[string]any{"age": 18, "premium": false, "country": "FR"},
+			want: []Condition{country},
+		},
+		{
+			name: "failing implication",
+			rule: Rule{Conditions: []Condition{premium, {Field: "age", Op: OperatorGTE, Value: 21, When: "$cond:0"}}},
+			data: map[string]any{"age": 18, "premium": true},
+			want: []Condition{{Field: "age", Op: OperatorGTE, Value: 21, When: "$cond:0"}},
+		},
+		{
+			name: "matching not rule",
+			rule: Rule{Conditions: []Condition{age, premium}, Logic: LogicNOT},
+			data: map[string]any{"age": 30, "premium": false},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().MinimalFailure(tt.rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	e := New()
+	e.Trace = true
+	data := map[string]any{"age": 30, "role": "user", "spend": 900, "limit": 1000}
+	tests := []struct {
+		name string
+		rule Rule
+		want []ConditionResult
+	}{
+		{
+			name: "AND short-circuits on failure",
+			rule: Rule{Conditions: []Condition{
+				{Field: "age", Op: OperatorGTE, Value: 18},
+				{Field: "role", Op: OperatorEQ, Value: "admin"},
+				{Field: "spend", Op: OperatorLT, Value: 500},
+			}},
+			want: []ConditionResult{
+				{Field: "age", Op: OperatorGTE, Expected: 18, Actual: 30, Matched: true},
+				{Field: "role", Op: OperatorEQ, Expected: "admin", Actual: "user", Matched: false},
+			},
+		},
+		{
+			name: "OR stops at first match",
+			rule: Rule{Logic: LogicOR, Conditions: []Condition{
+				{Field: "role", Op: OperatorEQ, Value: "admin"},
+				{Field: "spend", Op: OperatorGTE, ValueField: "limit", Percent: 80},
+				{Field: "age", Op: OperatorLT, Value: 18},
+			}},
+			want: []ConditionResult{
+				{Field: "role", Op: OperatorEQ, Expected: "admin", Actual: "user", Matched: false},
+				{Field: "spend", Op: OperatorGTE, Expected: 800.0, Actual: 900, Matched: true},
+			},
+		},
+		{
+			name: "negation and transforms",
+			rule: Rule{Conditions: []Condition{
+				{Field: "role", Op: OperatorEQ, Value: "USER", Transform: "upper", Negate: true},
+			}},
+			want: []ConditionResult{
+				{Field: "role", Op: OperatorEQ, Expected: "USER", Actual: "USER", Negated: true, Matched: false},
+			},
+		},
+		{
+			name: "grouped rule",
+			rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+				{Condition: &Condition{Field: "role", Op: OperatorEQ, Value: "admin"}},
+				{Condition: &Condition{Field: "age", Op: OperatorGT, Value: 21}},
+			}}},
+			want: []ConditionResult{
+				{Field: "role", Op: OperatorEQ, Expected: "admin", Actual: "user", Matched: false},
+				{Field: "age", Op: OperatorGT, Expected: 21, Actual: 30, Matched: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(tt.rule, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res.Trace, tt.want) {
+				t.Errorf("Trace = %+v, want %+v", res.Trace, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing field", func(t *testing.T) {
+		e := NewWithOptions(Options{MissingFieldBehavior: MissingAsNoMatch})
+		e.Trace = true
+		res, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "beta", Op: OperatorEQ, Value: true}}}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []ConditionResult{{Field: "beta", Op: OperatorEQ, Expected: true, Missing: true}}
+		if !reflect.DeepEqual(res.Trace, want) {
+			t.Errorf("Trace = %+v, want %+v", res.Trace, want)
+		}
+	})
+	t.Run("off by default", func(t *testing.T) {
+		res, err := New().Evaluate(Rule{Conditions: []Condition{{Field: "age", Op: OperatorGT, Value: 1}}}, data)
+		if err != nil || res.Trace != nil {
+			t.Errorf("Trace = %v, %v; want none", res.Trace, err)
+		}
+	})
+}
+
+func TestTraceOperandsResolvedOnce(t *testing.T) {
+	e := New()
+	e.Trace = true
+	calls := 0
+	e.Resolver = FieldResolverFunc(func(path string) (any, bool, error) {
+		calls++
+		return strings.Repeat("x", calls), path == "code", nil
+	})
+	items := []any{3, 4}
+	rule := Rule{Conditions: []Condition{
+		{Field: "code", Op: OperatorLen, Value: map[string]any{"op": "eq", "value": 1}},
+		{Field: "items", Op: OperatorAll, Value: Condition{Op: OperatorGT, Value: 2}},
+	}}
+	res, err := e.Evaluate(rule, map[string]any{"items": items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched || calls != 1 {
+		t.Fatalf("Matched = %v with %d resolver calls, want true with 1", res.Matched, calls)
+	}
+	if got := res.Trace[0]; got.Actual != "x" {
+		t.Errorf("len Actual = %v, want the value compared, x", got.Actual)
+	}
+	if got := res.Trace[len(res.Trace)-1]; got.Op != OperatorAll || !reflect.DeepEqual(got.Actual, items) {
+		t.Errorf("last record = %+v, want all with Actual %v", got, items)
+	}
+}
+
+func TestExplainAll(t *testing.T) {
+	e := New()
+	e.Trace = true
+	e.ExplainAll = true
+	data := map[string]any{"age": 30, "role": "user", "country": "US"}
+	age := Condition{Field: "age", Op: OperatorGTE, Value: 18}
+	admin := Condition{Field: "role", Op: OperatorEQ, Value: "admin"}
+	us := Condition{Field: "country", Op: OperatorEQ, Value: "US"}
+	tests := []struct {
+		name     string
+		rule     Rule
+		want     bool
+		wantExpl string
+		traced   int
+	}{
+		{
+			name: "or past the first match", rule: Rule{Logic: LogicOR, Conditions: []Condition{age, admin, us}},
+			want: true, wantExpl: "age gte 18 → true OR role eq admin → false OR country eq US → true", traced: 3,
+		},
+		{
+			name: "and past the first failure", rule: Rule{Conditions: []Condition{admin, age, us}},
+			want: false, wantExpl: "role eq admin → false AND age gte 18 → true AND country eq US → true", traced: 3,
+		},
+		{
+			name: "not", rule: Rule{Logic: LogicNOT, Conditions: []Condition{admin, age}},
+			want: true, wantExpl: "NOT (role eq admin → false AND age gte 18 → true)", traced: 2,
+		},
+		{
+			name: "or with no match", rule: Rule{Logic: LogicOR, Conditions: []Condition{admin}},
+			want: false, wantExpl: "role eq admin → false", traced: 1,
+		},
+		{
+			name: "groups", rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{{Condition: &us}, {Group: &Group{Items: []Item{{Condition: &admin}, {Condition: &age}}}}}}},
+			want: true, wantExpl: "(country eq US → true OR (role eq admin → false AND age gte 18 → true) → false) → true", traced: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(tt.rule, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+			if res.Explanation != tt.wantExpl {
+				t.Errorf("Explanation = %q, want %q", res.Explanation, tt.wantExpl)
+			}
+			if len(res.Trace) != tt.traced {
+				t.Errorf("traced %d conditions, want %d: %+v", len(res.Trace), tt.traced, res.Trace)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expr is a node of a parsed boolean expression: a *LogicExpr, *NotExpr or
+// *ConditionExpr.
+type Expr interface {
+	// String renders the node fully parenthesised, making its structure
+	// explicit.
+	String() string
+	expr()
+}
+
+// LogicExpr combines two or more operands with AND or OR.
+type LogicExpr struct {
+	Logic    Logic
+	Operands []Expr
+}
+
+// NotExpr negates its operand.
+type NotExpr struct {
+	X Expr
+}
+
+// ConditionExpr is a single comparison.
+type ConditionExpr struct {
+	Condition Condition
+}
+
+func (*LogicExpr) expr()     {}
+func (*NotExpr) expr()       {}
+func (*ConditionExpr) expr() {}
+
+func (x *LogicExpr) String() string {
+	sep := " && "
+	if x.Logic == LogicOR {
+		sep = " || "
+	}
+	parts := make([]string, len(x.Operands))
+	for i, op := range x.Operands {
+		parts[i] = op.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
+
+func (x *NotExpr) String() string { return "!" + x.X.String() }
+
+func (x *ConditionExpr) String() string {
+	c := x.Condition
+	return c.Field + " " + exprOperator(c.Op) + " " + exprLiteral(c.Value)
+}
+
+// exprSymbols maps expression comparison symbols to operators.
+var exprSymbols = map[string]Operator{
+	"==": OperatorEQ,
+	"!=": OperatorNE,
+	">":  OperatorGT,
+	">=": OperatorGTE,
+	"<":  OperatorLT,
+	"<=": OperatorLTE,
+}
+
+func exprOperator(op Operator) string {
+	for sym, o := range exprSymbols {
+		if o == op {
+			return sym
+		}
+	}
+	return string(op)
+}
+
+func exprLiteral(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strconv.Quote(x)
+	case nil:
+		return "null"
+	case []any:
+		parts := make([]string, len(x))
+		for i, item := range x {
+			parts[i] = exprLiteral(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return fmt.Sprint(v)
+}
+
+// ParseExpression parses a boolean expression such as
+//
+//	role == "admin" || score > 100 && !(country in ["FR", "DE"])
+//
+// A comparison is a field path, an operator and a literal value. Field paths
+// are dot-separated names of Unicode letters, digits and underscores, not
+// starting with a digit. Operators are ==, !=, >, >=, <, <= or the name of
+// any operator, e.g. in, contains or a custom one. Literals are
+// double-quoted strings, numbers, true, false, null and [lists].
+// Precedence, from tightest to loosest binding, follows common languages:
+//
+//  1. comparisons
+//  2. unary !
+//  3. &&
+//  4. ||
+//
+// so "a || b && c" parses as "a || (b && c)" and "!a && b" as "(!a) && b".
+// Parentheses override precedence.
+func ParseExpression(src string) (Expr, error) {
+	toks, err := lexExpression(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	x, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return x, nil
+}
+
+// EvaluateExpr evaluates a parsed expression against data, short-circuiting
+// && and ||.
+func (e *Engine) EvaluateExpr(ctx context.Context, x Expr, data map[string]any) (Result, error) {
+	matched, err := e.evalExpr(ctx, &evalState{}, x, data)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Matched: matched, Explanation: fmt.Sprintf("%s → %t", x, matched)}, nil
+}
+
+func (e *Engine) evalExpr(ctx context.Context, st *evalState, x Expr, data map[string]any) (bool, error) {
+	switch x := x.(type) {
+	case *ConditionExpr:
+		matched, _, err := e.evalCondition(ctx, st, x.Condition, data)
+		return matched, err
+	case *NotExpr:
+		matched, err := e.evalExpr(ctx, st, x.X, data)
+		return !matched && err == nil, err
+	case *LogicExpr:
+		for _, op := range x.Operands {
+			matched, err := e.evalExpr(ctx, st, op, data)
+			if err != nil {
+				return false, err
+			}
+			if matched == (x.Logic == LogicOR) {
+				return matched, nil
+			}
+		}
+		return x.Logic != LogicOR, nil
+	}
+	return false, fmt.Errorf("unknown expression node %T", x)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexExpression splits src into tokens.
+func lexExpression(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		r, _ := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("expression: unterminated string at offset %d", i)
+			}
+			toks = append(toks, token{tokString, src[i : j+1], i})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '-' || c == '.':
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				if (src[j] == '+' || src[j] == '-') && src[j-1] != 'e' && src[j-1] != 'E' {
+					break
+				}
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(src) {
+				r, n := utf8.DecodeRuneInString(src[j:])
+				if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += n
+			}
+			toks = append(toks, token{tokIdent, src[i:j], i})
+			i = j
+		default:
+			sym := ""
+			for _, s := range []string{"&&", "||", "==", "!=", ">=", "<=", ">", "<", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(src[i:], s) {
+					sym = s
+					break
+				}
+			}
+			if sym == "" {
+				return nil, fmt.Errorf("expression: unexpected character %q at offset %d", r, i)
+			}
+			toks = append(toks, token{tokSymbol, sym, i})
+			i += len(sym)
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+type exprParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *exprParser) peek() token { return p.toks[p.pos] }
+
+func (p *exprParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) isSymbol(s string) bool {
+	t := p.peek()
+	return t.kind == tokSymbol && t.text == s
+}
+
+func (p *exprParser) unexpected(t token) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("expression: unexpected end of input")
+	}
+	return fmt.Errorf("expression: unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *exprParser) parseOr() (Expr, error) {
+	return p.parseLogic(LogicOR, "||", p.parseAnd)
+}
+
+func (p *exprParser) parseAnd() (Expr, error) {
+	return p.parseLogic(LogicAND, "&&", p.parseUnary)
+}
+
+// parseLogic parses one or more operands joined by sym into a LogicExpr.
+func (p *exprParser) parseLogic(logic Logic, sym string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Expr{first}
+	for p.isSymbol(sym) {
+		p.next()
+		x, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, x)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &LogicExpr{Logic: logic, Operands: operands}, nil
+}
+
+func (p *exprParser) parseUnary() (Expr, error) {
+	if p.isSymbol("!") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{X: x}, nil
+	}
+	if p.isSymbol("(") {
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isSymbol(")") {
+			return nil, p.unexpected(p.peek())
+		}
+		p.next()
+		return x, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (Expr, error) {
+	field := p.next()
+	if field.kind != tokIdent {
+		return nil, p.unexpected(field)
+	}
+	opTok := p.next()
+	var op Operator
+	switch {
+	case opTok.kind == tokSymbol && exprSymbols[opTok.text] != "":
+		op = exprSymbols[opTok.text]
+	case opTok.kind == tokIdent:
+		op = Operator(opTok.text)
+	default:
+		return nil, p.unexpected(opTok)
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return &ConditionExpr{Condition: Condition{Field: field.text, Op: op, Value: value}}, nil
+}
+
+func (p *exprParser) parseValue() (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("expression: invalid string %s at offset %d", t.text, t.pos)
+		}
+		return s, nil
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expression: invalid number %q at offset %d", t.text, t.pos)
+		}
+		return f, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	case tokSymbol:
+		if t.text == "[" {
+			return p.parseList()
+		}
+	}
+	return nil, p.unexpected(t)
+}
+
+func (p *exprParser) parseList() (any, error) {
+	items := []any{}
+	if p.isSymbol("]") {
+		p.next()
+		return items, nil
+	}
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		t := p.next()
+		if t.kind == tokSymbol && t.text == "]" {
+			return items, nil
+		}
+		if t.kind != tokSymbol || t.text != "," {
+			return nil, p.unexpected(t)
+		}
+	}
+}
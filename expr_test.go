@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseExpressionPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		wantErr bool
+	}{
+		{"and binds tighter than or", `a == 1 || b == 2 && c == 3`, `(a == 1 || (b == 2 && c == 3))`, false},
+		{"and before or", `a == 1 && b == 2 || c == 3`, `((a == 1 && b == 2) || c == 3)`, false},
+		{"chains flatten", `a == 1 || b == 2 || c == 3`, `(a == 1 || b == 2 || c == 3)`, false},
+		{"not binds tighter than and", `!a == 1 && b == 2`, `(!a == 1 && b == 2)`, false},
+		{"parentheses override", `(a == 1 || b == 2) && c == 3`, `((a == 1 || b == 2) && c == 3)`, false},
+		{"negated group", `!(a == 1 || b == 2)`, `!(a == 1 || b == 2)`, false},
+		{"word operators and lists", `country in ["FR", "DE"] && tags contains "x"`, `(country in ["FR", "DE"] && tags contains "x")`, false},
+		{"literals", `a != null || b >= -1.5 || c == true`, `(a != null || b >= -1.5 || c == true)`, false},
+		{"unicode identifiers", "pa\u00eds == \"FR\" && gr\u00f6\u00dfe.\u5024 > 1", "(pa\u00eds == \"FR\" && gr\u00f6\u00dfe.\u5024 > 1)", false},
+		{"unexpected unicode symbol", "a \u2192 1", "", true},
+		{"missing operand", `a == 1 ||`, "", true},
+		{"unbalanced", `(a == 1`, "", true},
+		{"trailing tokens", `a == 1 b`, "", true},
+		{"unterminated string", `a == "x`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := ParseExpression(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && x.String() != tt.want {
+				t.Errorf("ParseExpression() = %s, want %s", x, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionTree(t *testing.T) {
+	x, err := ParseExpression(`a == 1 || !b > 2 && c == "x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	or, ok := x.(*LogicExpr)
+	if !ok || or.Logic != LogicOR || len(or.Operands) != 2 {
+		t.Fatalf("root = %#v, want OR with two operands", x)
+	}
+	and, ok := or.Operands[1].(*LogicExpr)
+	if !ok || and.Logic != LogicAND || len(and.Operands) != 2 {
+		t.Fatalf("right = %#v, want AND with two operands", or.Operands[1])
+	}
+	not, ok := and.Operands[0].(*NotExpr)
+	if !ok {
+		t.Fatalf("and[0] = %#v, want NOT", and.Operands[0])
+	}
+	if c := not.X.(*ConditionExpr).Condition; c.Field != "b" || c.Op != OperatorGT || c.Value != 2.0 {
+		t.Errorf("negated condition = %+v", c)
+	}
+}
+
+func TestEvaluateExpr(t *testing.T) {
+	e := New()
+	data := map[string]any{"a": 0, "b": 2, "c": 3, "gr\u00f6\u00dfe": 4}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`a == 1 || b == 2 && c == 3`, true},
+		{`(a == 1 || b == 2) && c == 4`, false},
+		{`!(a == 1) && b == 2`, true},
+		{`a == 0 || missing == 1`, true},
+		{"gr\u00f6\u00dfe > 3", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			x, err := ParseExpression(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := e.EvaluateExpr(context.Background(), x, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("EvaluateExpr() = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
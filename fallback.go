@@ -0,0 +1,26 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EvaluateWithFallback evaluates primary against data and, only if that
+// fails with an error, evaluates fallback instead, so a rule that depends on
+// flaky inputs can degrade to a simpler one. A primary rule that evaluates
+// without matching is a result, not a failure, and is returned as is. A
+// result from the fallback rule has Fallback set and the primary's error in
+// PrimaryError. If both rules fail, both errors are returned.
+func (e *Engine) EvaluateWithFallback(primary, fallback Rule, data map[string]any) (Result, error) {
+	res, err := e.Evaluate(primary, data)
+	if err == nil {
+		return res, nil
+	}
+	res, ferr := e.Evaluate(fallback, data)
+	if ferr != nil {
+		return Result{}, errors.Join(fmt.Errorf("primary rule: %w", err), fmt.Errorf("fallback rule: %w", ferr))
+	}
+	res.Fallback = true
+	res.PrimaryError = err.Error()
+	return res, nil
+}
@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEvaluateWithFallback(t *testing.T) {
+	primary := Rule{Conditions: []Condition{{Field: "risk.score", Op: OperatorLT, Value: 50}}}
+	fallback := Rule{Conditions: []Condition{{Field: "amount", Op: OperatorLT, Value: 100}}}
+	tests := []struct {
+		name         string
+		fallback     Rule
+		data         map[string]any
+		want         bool
+		wantFallback bool
+		wantErr      bool
+	}{
+		{name: "primary matches", fallback: fallback, data: map[string]any{"risk": map[string]any{"score": 10}, "amount": 500}, want: true},
+		{name: "primary does not match", fallback: fallback, data: map[string]any{"risk": map[string]any{"score": 90}, "amount": 50}, want: false},
+		{name: "primary errors, fallback matches", fallback: fallback, data: map[string]any{"amount": 50}, want: true, wantFallback: true},
+		{name: "primary errors, fallback does not match", fallback: fallback, data: map[string]any{"amount": 500}, want: false, wantFallback: true},
+		{name: "both error", fallback: Rule{Conditions: []Condition{{Field: "amount", Op: "approx", Value: 1}}}, data: map[string]any{"amount": 5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := New().EvaluateWithFallback(primary, tt.fallback, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want || res.Fallback != tt.wantFallback {
+				t.Errorf("got Matched %v Fallback %v, want %v %v", res.Matched, res.Fallback, tt.want, tt.wantFallback)
+			}
+			if tt.wantFallback && res.PrimaryError == "" {
+				t.Error("PrimaryError not set")
+			}
+			if tt.wantErr && !(errors.Is(err, ErrFieldNotFound) && errors.Is(err, ErrUnknownOperator)) {
+				t.Errorf("error = %v, want both rules' errors", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QuantileRefPrefix marks a condition value that Filter replaces with a
+// quantile of the condition's field across the batch, e.g.
+// {"field":"score","op":"gt","value":"$quantile:0.9"} selects rows scoring
+// above the batch's 90th percentile, roughly its top 10%.
+const QuantileRefPrefix = "$quantile:"
+
+// Filter returns the rows matching rule, in order. It makes two passes: the
+// first computes every "$quantile:q" value from the distribution of its
+// condition's field over all rows, with linear interpolation between the
+// closest ranks, and the second evaluates the rule against each row with
+// those thresholds in place. Thresholds therefore depend on the whole batch,
+// and rows missing the field do not contribute to them. Quantile values are
+// an error outside Filter.
+func (e *Engine) Filter(rule Rule, rows []map[string]any) ([]map[string]any, error) {
+	resolved, err := e.resolveQuantiles(rule, rows)
+	if err != nil {
+		return nil, err
+	}
+	var out []map[string]any
+	for i, row := range rows {
+		res, err := e.Evaluate(resolved, row)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		if res.Matched {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+// resolveQuantiles returns a copy of rule with "$quantile:" values, in its
+// conditions and in the conditions of its Root group, replaced by the
+// quantiles of their fields over rows.
+func (e *Engine) resolveQuantiles(rule Rule, rows []map[string]any) (Rule, error) {
+	conds := slices.Clone(rule.Conditions)
+	for i, c := range conds {
+		var err error
+		if conds[i], err = e.resolveQuantile(c, rows); err != nil {
+			return Rule{}, err
+		}
+	}
+	rule.Conditions = conds
+	if rule.Root != nil {
+		root, err := e.resolveGroupQuantiles(rule.Root, rows)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Root = root
+	}
+	return rule, nil
+}
+
+// resolveGroupQuantiles returns a copy of g with its "$quantile:" values
+// resolved, leaving g unmodified.
+func (e *Engine) resolveGroupQuantiles(g *Group, rows []map[string]any) (*Group, error) {
+	out := &Group{Logic: g.Logic, Items: make([]Item, len(g.Items))}
+	for i, item := range g.Items {
+		if item.Condition != nil {
+			c, err := e.resolveQuantile(*item.Condition, rows)
+			if err != nil {
+				return nil, err
+			}
+			item.Condition = &c
+		}
+		if item.Group != nil {
+			sub, err := e.resolveGroupQuantiles(item.Group, rows)
+			if err != nil {
+				return nil, err
+			}
+			item.Group = sub
+		}
+		out.Items[i] = item
+	}
+	return out, nil
+}
+
+// resolveQuantile returns c with a "$quantile:" value replaced by the
+// quantile of c's field over rows.
+func (e *Engine) resolveQuantile(c Condition, rows []map[string]any) (Condition, error) {
+	s, ok := c.Value.(string)
+	if !ok || !strings.HasPrefix(s, QuantileRefPrefix) {
+		return c, nil
+	}
+	q, err := strconv.ParseFloat(strings.TrimPrefix(s, QuantileRefPrefix), 64)
+	if err != nil || q < 0 || q > 1 {
+		return Condition{}, fmt.Errorf("invalid quantile %q: want a number from 0 to 1", s)
+	}
+	var values []float64
+	for j, row := range rows {
+		v, ok, err := e.lookup(row, c.Field)
+		if err != nil {
+			return Condition{}, fmt.Errorf("row %d: %w", j, err)
+		}
+		if !ok {
+			continue
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return Condition{}, fmt.Errorf("row %d: quantile requires numeric field %q", j, c.Field)
+		}
+		values = append(values, f)
+	}
+	if len(values) == 0 {
+		return Condition{}, fmt.Errorf("quantile of field %q: no values in batch", c.Field)
+	}
+	c.Value = quantile(values, q)
+	return c, nil
+}
+
+// quantile returns the q-quantile of values, interpolating linearly between
+// the closest ranks. values is sorted in place.
+func quantile(values []float64, q float64) float64 {
+	slices.Sort(values)
+	h := float64(len(values)-1) * q
+	lo := math.Floor(h)
+	hi := math.Min(lo+1, float64(len(values)-1))
+	return values[int(lo)] + (h-lo)*(values[int(hi)]-values[int(lo)])
+}
@@ -0,0 +1,89 @@
+package rules
+
+import "testing"
+
+func TestFilterQuantile(t *testing.T) {
+	var rows []map[string]any
+	for i := 1; i <= 20; i++ {
+		rows = append(rows, map[string]any{"id": i, "score": i * 10, "active": i%2 == 0})
+	}
+	tests := []struct {
+		name string
+		rule Rule
+		want []int
+	}{
+		{"top 10%", Rule{Conditions: []Condition{{Field: "score", Op: OperatorGT, Value: "$quantile:0.9"}}}, []int{19, 20}},
+		{"bottom quartile", Rule{Conditions: []Condition{{Field: "score", Op: OperatorLTE, Value: "$quantile:0.25"}}}, []int{1, 2, 3, 4, 5}},
+		{"median with other conditions", Rule{Conditions: []Condition{
+			{Field: "score", Op: OperatorGTE, Value: "$quantile:0.5"},
+			{Field: "active", Op: OperatorEQ, Value: true},
+		}}, []int{12, 14, 16, 18, 20}},
+		{"maximum", Rule{Conditions: []Condition{{Field: "score", Op: OperatorGTE, Value: "$quantile:1"}}}, []int{20}},
+		{"grouped rule", Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+			{Condition: &Condition{Field: "id", Op: OperatorEQ, Value: 1}},
+			{Group: &Group{Items: []Item{
+				{Condition: &Condition{Field: "score", Op: OperatorGT, Value: "$quantile:0.9"}},
+				{Condition: &Condition{Field: "active", Op: OperatorEQ, Value: true}},
+			}}},
+		}}}, []int{1, 20}},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Filter(tt.rule, rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []int
+			for _, row := range got {
+				ids = append(ids, row["id"].(int))
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Filter() ids = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("Filter() ids = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterQuantileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		rows  []map[string]any
+	}{
+		{"out of range", "$quantile:1.5", []map[string]any{{"score": 1}}},
+		{"not a number", "$quantile:top", []map[string]any{{"score": 1}}},
+		{"non numeric field", "$quantile:0.5", []map[string]any{{"score": "high"}}},
+		{"no values", "$quantile:0.5", []map[string]any{{"other": 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "score", Op: OperatorGT, Value: tt.value}}}
+			if _, err := New().Filter(rule, tt.rows); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestQuantileOutsideFilter(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "score", Op: OperatorGT, Value: "$quantile:0.9"}}}
+	if _, err := Evaluate(rule, map[string]any{"score": 1}); err == nil {
+		t.Error("expected error for quantile outside Filter")
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	tests := map[float64]float64{0: 1, 0.5: 2.5, 0.9: 3.7, 1: 4}
+	for q, want := range tests {
+		if got := quantile(values, q); got < want-1e-9 || got > want+1e-9 {
+			t.Errorf("quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ConditionGraph is a set of named conditions and of named nodes combining
+// other nodes, forming a directed acyclic graph, so that a sub-expression
+// shared by several policies is written, and evaluated, once:
+//
+//	{"nodes":{
+//	  "adult":    {"condition":{"field":"age","op":"gte","value":18}},
+//	  "verified": {"condition":{"field":"kyc","op":"eq","value":true}},
+//	  "can_buy":  {"logic":"and","refs":["adult","verified"]},
+//	  "can_sell": {"logic":"and","refs":["verified","merchant"]},
+//	  ...}}
+type ConditionGraph struct {
+	Nodes map[string]GraphNode `json:"nodes"`
+}
+
+// GraphNode is a node of a ConditionGraph: exactly one of Condition or Refs
+// is set. Refs names the nodes combined with Logic, which defaults to AND.
+type GraphNode struct {
+	Condition *Condition `json:"condition,omitempty"`
+	Logic     Logic      `json:"logic,omitempty"`
+	Refs      []string   `json:"refs,omitempty"`
+}
+
+// EvaluateGraph evaluates the named roots of g against data, returning a
+// result for each. Every node is evaluated at most once per call, however
+// many roots or nodes refer to it; logic nodes short-circuit like rules, so
+// some nodes may not be evaluated at all. The graph is checked first:
+// unknown references, malformed nodes and cycles are an error.
+func (e *Engine) EvaluateGraph(ctx context.Context, g ConditionGraph, roots []string, data map[string]any) (map[string]Result, error) {
+	if err := g.check(); err != nil {
+		return nil, err
+	}
+	ev := &graphEval{e: e, g: g, st: &evalState{}, data: data, memo: make(map[string]Result)}
+	results := make(map[string]Result, len(roots))
+	for _, name := range roots {
+		if _, ok := g.Nodes[name]; !ok {
+			return nil, fmt.Errorf("condition graph: unknown node %q", name)
+		}
+		res, err := ev.node(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = res
+	}
+	return results, nil
+}
+
+// check reports malformed nodes, unknown references and cycles.
+func (g ConditionGraph) check() error {
+	names := slices.Sorted(maps.Keys(g.Nodes))
+	for _, name := range names {
+		n := g.Nodes[name]
+		if (n.Condition == nil) == (len(n.Refs) == 0) {
+			return fmt.Errorf("condition graph: node %q must set exactly one of condition or refs", name)
+		}
+		if err := validateLogic(n.Logic); err != nil {
+			return fmt.Errorf("condition graph: node %q: %w", name, err)
+		}
+		for _, ref := range n.Refs {
+			if _, ok := g.Nodes[ref]; !ok {
+				return fmt.Errorf("condition graph: node %q references unknown node %q", name, ref)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(g.Nodes))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			i := slices.Index(path, name)
+			return fmt.Errorf("condition graph: cycle %s", strings.Join(append(path[i:], name), " → "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, ref := range g.Nodes[name].Refs {
+			if err := visit(ref); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// graphEval evaluates the nodes of a checked graph, memoising their
+// results.
+type graphEval struct {
+	e    *Engine
+	g    ConditionGraph
+	st   *evalState
+	data map[string]any
+	memo map[string]Result
+}
+
+// node evaluates the named node. Its explanation is the condition's, or
+// the outcome of each reference evaluated, e.g.
+// "(adult → true AND verified → false) → false".
+func (ev *graphEval) node(ctx context.Context, name string) (Result, error) {
+	if res, ok := ev.memo[name]; ok {
+		return res, nil
+	}
+	n := ev.g.Nodes[name]
+	if n.Condition != nil {
+		matched, expl, err := ev.e.evalCondition(ctx, ev.st, *n.Condition, ev.data)
+		if err != nil {
+			return Result{}, fmt.Errorf("node %q: %w", name, err)
+		}
+		ev.memo[name] = Result{Matched: matched, Explanation: expl}
+		return ev.memo[name], nil
+	}
+	logic := n.Logic
+	if logic == "" {
+		logic = LogicAND
+	}
+	matched := logic != LogicOR
+	parts := make([]string, 0, len(n.Refs))
+	for _, ref := range n.Refs {
+		res, err := ev.node(ctx, ref)
+		if err != nil {
+			return Result{}, err
+		}
+		parts = append(parts, fmt.Sprintf("%s → %t", ref, res.Matched))
+		if res.Matched == (logic == LogicOR) {
+			matched = res.Matched
+			if !ev.e.ExplainAll {
+				break
+			}
+		}
+	}
+	sep := " AND "
+	if logic == LogicOR {
+		sep = " OR "
+	}
+	expl := "(" + strings.Join(parts, sep) + ")"
+	if logic == LogicNOT {
+		matched = !matched
+		expl = "NOT " + expl
+	}
+	ev.memo[name] = Result{Matched: matched, Explanation: fmt.Sprintf("%s → %t", expl, matched)}
+	return ev.memo[name], nil
+}
@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateGraph(t *testing.T) {
+	e := New()
+	calls := 0
+	e.Register("expensive", func(a, b any) (bool, error) {
+		calls++
+		return equal(a, b), nil
+	})
+	var g ConditionGraph
+	err := json.Unmarshal([]byte(`{"nodes":{
+		"adult":    {"condition":{"field":"age","op":"gte","value":18}},
+		"verified": {"condition":{"field":"kyc","op":"expensive","value":"passed"}},
+		"merchant": {"condition":{"field":"role","op":"eq","value":"merchant"}},
+		"blocked":  {"condition":{"field":"blocked","op":"eq","value":true}},
+		"trusted":  {"logic":"and","refs":["adult","verified"]},
+		"can_buy":  {"logic":"and","refs":["trusted","allowed"]},
+		"can_sell": {"logic":"and","refs":["verified","merchant","allowed"]},
+		"allowed":  {"logic":"not","refs":["blocked"]}
+	}}`), &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]any{"age": 30, "kyc": "passed", "role": "merchant", "blocked": false}
+	results, err := e.EvaluateGraph(context.Background(), g, []string{"can_buy", "can_sell", "verified"}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"can_buy", "can_sell", "verified"} {
+		if !results[name].Matched {
+			t.Errorf("%s = %+v, want match", name, results[name])
+		}
+	}
+	if calls != 1 {
+		t.Errorf("shared node evaluated %d times, want 1", calls)
+	}
+	if want := "(trusted → true AND allowed → true) → true"; results["can_buy"].Explanation != want {
+		t.Errorf("explanation = %q, want %q", results["can_buy"].Explanation, want)
+	}
+
+	data["age"] = 16
+	results, err = e.EvaluateGraph(context.Background(), g, []string{"can_buy", "can_sell"}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["can_buy"].Matched || !results["can_sell"].Matched {
+		t.Errorf("results = %+v, want only can_sell to match", results)
+	}
+}
+
+func TestEvaluateGraphErrors(t *testing.T) {
+	cond := &Condition{Field: "a", Op: OperatorEQ, Value: 1}
+	tests := []struct {
+		name  string
+		nodes map[string]GraphNode
+		roots []string
+		want  string
+	}{
+		{name: "cycle", nodes: map[string]GraphNode{
+			"a": {Refs: []string{"b"}}, "b": {Refs: []string{"c"}}, "c": {Refs: []string{"a"}},
+		}, roots: []string{"a"}, want: "cycle a → b → c → a"},
+		{name: "self reference", nodes: map[string]GraphNode{"a": {Logic: LogicOR, Refs: []string{"x", "a"}}, "x": {Condition: cond}}, roots: []string{"x"}, want: "cycle a → a"},
+		{name: "unknown reference", nodes: map[string]GraphNode{"a": {Refs: []string{"b"}}}, roots: []string{"a"}, want: `references unknown node "b"`},
+		{name: "unknown root", nodes: map[string]GraphNode{"a": {Condition: cond}}, roots: []string{"z"}, want: `unknown node "z"`},
+		{name: "malformed node", nodes: map[string]GraphNode{"a": {Condition: cond, Refs: []string{"a"}}}, roots: []string{"a"}, want: "exactly one of condition or refs"},
+		{name: "unknown logic", nodes: map[string]GraphNode{"a": {Logic: "xor", Refs: []string{"b"}}, "b": {Condition: cond}}, roots: []string{"a"}, want: `unknown logic "xor"`},
+		{name: "condition error", nodes: map[string]GraphNode{"a": {Refs: []string{"b"}}, "b": {Condition: &Condition{Field: "missing", Op: OperatorEQ}}}, roots: []string{"a"}, want: `node "b": field "missing" not found`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().EvaluateGraph(context.Background(), ConditionGraph{Nodes: tt.nodes}, tt.roots, map[string]any{"a": 1})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Group is a node of a nested rule: items combined with Logic, which
+// defaults to AND. Groups express parenthesised logic such as
+// "(A OR B) AND C" that a flat condition list cannot.
+type Group struct {
+	Logic Logic  `json:"logic,omitempty"`
+	Items []Item `json:"items"`
+}
+
+// Item is a group member: exactly one of Condition or Group is set.
+type Item struct {
+	Condition *Condition `json:"condition,omitempty"`
+	Group     *Group     `json:"group,omitempty"`
+}
+
+// ExplanationNode is one node of the explanation tree of a grouped rule.
+// Condition nodes carry their explanation in Text; group nodes carry their
+// Logic and the Children evaluated before the group short-circuited.
+type ExplanationNode struct {
+	Logic    Logic              `json:"logic,omitempty"`
+	Text     string             `json:"text,omitempty"`
+	Matched  bool               `json:"matched"`
+	Children []*ExplanationNode `json:"children,omitempty"`
+}
+
+// String renders the node on one line, e.g.
+// "(role eq admin → false OR age gt 18 → true) → true".
+func (n *ExplanationNode) String() string {
+	if n.Logic == "" {
+		return n.Text
+	}
+	sep := " AND "
+	if n.Logic == LogicOR {
+		sep = " OR "
+	}
+	parts := make([]string, len(n.Children))
+	for i, c := range n.Children {
+		parts[i] = c.String()
+	}
+	s := "(" + strings.Join(parts, sep) + ")"
+	if n.Logic == LogicNOT {
+		s = "NOT " + s
+	}
+	return fmt.Sprintf("%s → %t", s, n.Matched)
+}
+
+// evalGroup evaluates g, short-circuiting per its logic.
+func (e *Engine) evalGroup(ctx context.Context, st *evalState, g *Group, data map[string]any) (*ExplanationNode, error) {
+	logic := g.Logic
+	if logic == "" {
+		logic = LogicAND
+	}
+	node := &ExplanationNode{Logic: logic, Matched: logic != LogicOR}
+	for i, item := range g.Items {
+		var child *ExplanationNode
+		switch {
+		case item.Condition != nil && item.Group == nil:
+			if item.Condition.When != "" {
+				return nil, fmt.Errorf("group item %d: when is not supported in groups", i)
+			}
+			matched, expl, err := e.evalCondition(ctx, st, *item.Condition, data)
+			if err != nil {
+				return nil, err
+			}
+			child = &ExplanationNode{Text: expl, Matched: matched}
+		case item.Group != nil && item.Condition == nil:
+			var err error
+			if child, err = e.evalGroup(ctx, st, item.Group, data); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("group item %d must set exactly one of condition or group", i)
+		}
+		node.Children = append(node.Children, child)
+		if child.Matched == (logic == LogicOR) {
+			node.Matched = child.Matched
+			if !e.ExplainAll {
+				break
+			}
+		}
+	}
+	if logic == LogicNOT {
+		node.Matched = !node.Matched
+	}
+	return node, nil
+}
+
+// conditions appends the conditions of g and its descendants to dst in
+// depth-first order.
+func (g *Group) conditions(dst []Condition) []Condition {
+	for _, item := range g.Items {
+		switch {
+		case item.Condition != nil:
+			dst = append(dst, *item.Condition)
+		case item.Group != nil:
+			dst = item.Group.conditions(dst)
+		}
+	}
+	return dst
+}
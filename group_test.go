@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const groupedRuleJSON = `{
+	"conditions": null,
+	"root": {
+		"logic": "and",
+		"items": [
+			{"group": {"logic": "or", "items": [
+				{"condition": {"field": "role", "op": "eq", "value": "admin"}},
+				{"condition": {"field": "age", "op": "gt", "value": 18}}
+			]}},
+			{"condition": {"field": "active", "op": "eq", "value": true}}
+		]
+	}
+}`
+
+func TestGroupJSONRoundTrip(t *testing.T) {
+	var rule Rule
+	if err := json.Unmarshal([]byte(groupedRuleJSON), &rule); err != nil {
+		t.Fatal(err)
+	}
+	if rule.Root == nil || len(rule.Root.Items) != 2 || rule.Root.Items[0].Group == nil || len(rule.Root.Items[0].Group.Items) != 2 {
+		t.Fatalf("decoded rule = %+v, want two-level nesting", rule)
+	}
+	b, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Rule
+	if err := json.Unmarshal(b, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rule, again) {
+		t.Errorf("round trip changed rule:\n%s", b)
+	}
+}
+
+func TestEvaluateGroups(t *testing.T) {
+	var rule Rule
+	if err := json.Unmarshal([]byte(groupedRuleJSON), &rule); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		data     map[string]any
+		want     bool
+		wantExpl string
+	}{
+		{
+			name:     "first alternative",
+			data:     map[string]any{"role": "admin", "active": true},
+			want:     true,
+			wantExpl: "((role eq admin → true) → true AND active eq true → true) → true",
+		},
+		{
+			name:     "second alternative",
+			data:     map[string]any{"role": "user", "age": 30, "active": true},
+			want:     true,
+			wantExpl: "((role eq admin → false OR age gt 18 → true) → true AND active eq true → true) → true",
+		},
+		{
+			name:     "no alternative short-circuits and",
+			data:     map[string]any{"role": "user", "age": 12},
+			want:     false,
+			wantExpl: "((role eq admin → false OR age gt 18 → false) → false) → false",
+		},
+		{
+			name:     "inactive",
+			data:     map[string]any{"role": "admin", "active": false},
+			want:     false,
+			wantExpl: "((role eq admin → true) → true AND active eq true → false) → false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want || res.Explanation != tt.wantExpl {
+				t.Errorf("Evaluate() = %v %q, want %v %q", res.Matched, res.Explanation, tt.want, tt.wantExpl)
+			}
+			if res.Tree == nil || res.Tree.Matched != res.Matched || res.Tree.Children[0].Logic != LogicOR {
+				t.Errorf("Tree = %+v, want nested explanation tree", res.Tree)
+			}
+		})
+	}
+}
+
+func TestEvaluateGroupNot(t *testing.T) {
+	rule := Rule{Root: &Group{Items: []Item{
+		{Condition: &Condition{Field: "age", Op: OperatorGTE, Value: 18}},
+		{Group: &Group{Logic: LogicNOT, Items: []Item{
+			{Condition: &Condition{Field: "blocked", Op: OperatorEQ, Value: true}},
+		}}},
+	}}}
+	for blocked, want := range map[bool]bool{false: true, true: false} {
+		res, err := Evaluate(rule, map[string]any{"age": 20, "blocked": blocked})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != want {
+			t.Errorf("blocked=%v: Matched = %v, want %v (%s)", blocked, res.Matched, want, res.Explanation)
+		}
+	}
+}
+
+func TestEvaluateGroupErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		root *Group
+	}{
+		{"empty item", &Group{Items: []Item{{}}}},
+		{"both set", &Group{Items: []Item{{Condition: &Condition{Field: "a", Op: OperatorEQ, Value: 1}, Group: &Group{}}}}},
+		{"when in group", &Group{Items: []Item{{Condition: &Condition{Field: "a", Op: OperatorEQ, Value: 1, When: "$cond:0"}}}}},
+		{"nested error", &Group{Items: []Item{{Group: &Group{Items: []Item{{Condition: &Condition{Field: "missing", Op: OperatorEQ, Value: 1}}}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Evaluate(Rule{Root: tt.root}, map[string]any{"a": 1}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestGroupToCEL(t *testing.T) {
+	var rule Rule
+	if err := json.Unmarshal([]byte(groupedRuleJSON), &rule); err != nil {
+		t.Fatal(err)
+	}
+	got, err := rule.ToCEL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `(role == "admin" || age > 18) && active == true`; got != want {
+		t.Errorf("ToCEL() = %s, want %s", got, want)
+	}
+}
+
+func TestGroupConfidence(t *testing.T) {
+	rule := Rule{Root: &Group{Items: []Item{
+		{Group: &Group{Logic: LogicOR, Items: []Item{
+			{Condition: &Condition{Field: "name", Op: OperatorSimilar, Value: "Jonathan"}},
+			{Condition: &Condition{Field: "vip", Op: OperatorEQ, Value: true}},
+		}}},
+		{Condition: &Condition{Field: "country", Op: OperatorEQ, Value: "US"}},
+	}}}
+	got, err := New().Confidence(rule, map[string]any{"name": "Jonathon", "vip": false, "country": "US"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0.875 {
+		t.Errorf("Confidence = %v, want 0.875", got)
+	}
+}
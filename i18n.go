@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// OperatorIsTimezone matches a string field naming an IANA time zone, such
+// as "Europe/Paris" or "UTC", as loadable by time.LoadLocation. The empty
+// string and "Local" are rejected since they do not name a zone. The
+// condition value is ignored.
+const OperatorIsTimezone Operator = "is_timezone"
+
+// OperatorIsLocale matches a string field that is a well-formed BCP 47
+// language tag, such as "en", "en-US" or "zh-Hant-TW". Only the tag's syntax
+// is checked, not whether its subtags are registered. The condition value is
+// ignored.
+const OperatorIsLocale Operator = "is_locale"
+
+func isTimezone(a, _ any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for is_timezone")
+	}
+	if s == "" || s == "Local" {
+		return false, nil
+	}
+	_, err := time.LoadLocation(s)
+	return err == nil, nil
+}
+
+// localePattern matches BCP 47 language tags: a language, optional script
+// and region, then variants, extensions and a private-use section.
+var localePattern = regexp.MustCompile(`(?i)^[a-z]{2,3}` +
+	`(-[a-z]{4})?` +
+	`(-([a-z]{2}|[0-9]{3}))?` +
+	`(-([a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` +
+	`(-[a-wyz0-9](-[a-z0-9]{2,8})+)*` +
+	`(-x(-[a-z0-9]{1,8})+)?$`)
+
+func isLocale(a, _ any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for is_locale")
+	}
+	return localePattern.MatchString(s), nil
+}
@@ -0,0 +1,75 @@
+package rules
+
+import "testing"
+
+func TestIsTimezone(t *testing.T) {
+	tests := []struct {
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{"Europe/Paris", true, false},
+		{"America/New_York", true, false},
+		{"UTC", true, false},
+		{"Mars/Olympus", false, false},
+		{"europe/paris/", false, false},
+		{"../etc/passwd", false, false},
+		{"", false, false},
+		{"Local", false, false},
+		{42, false, true},
+	}
+	for _, tt := range tests {
+		got, err := isTimezone(tt.value, nil)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("isTimezone(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("isTimezone(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsLocale(t *testing.T) {
+	tests := []struct {
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{"en", true, false},
+		{"en-US", true, false},
+		{"zh-Hant-TW", true, false},
+		{"es-419", true, false},
+		{"de-CH-1996", true, false},
+		{"en-US-u-ca-gregory", true, false},
+		{"en-x-private", true, false},
+		{"en_US", false, false},
+		{"english", false, false},
+		{"en-", false, false},
+		{"e", false, false},
+		{"", false, false},
+		{nil, false, true},
+	}
+	for _, tt := range tests {
+		got, err := isLocale(tt.value, nil)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("isLocale(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("isLocale(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTimezoneLocaleRule(t *testing.T) {
+	rule := Rule{Conditions: []Condition{
+		{Field: "tz", Op: OperatorIsTimezone},
+		{Field: "locale", Op: OperatorIsLocale},
+	}}
+	res, err := New().Evaluate(rule, map[string]any{"tz": "Asia/Tokyo", "locale": "ja-JP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Errorf("Evaluate() = %+v, want match", res)
+	}
+}
@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CondRefPrefix marks a Condition.When reference to an earlier condition by
+// its index within the rule, e.g. "$cond:0".
+const CondRefPrefix = "$cond:"
+
+// antecedents reports which conditions are referenced by a later
+// condition's When, validating that every reference points backwards.
+func antecedents(conds []Condition) ([]bool, error) {
+	refs := make([]bool, len(conds))
+	for i, c := range conds {
+		if c.When == "" {
+			continue
+		}
+		j, err := condIndex(c.When)
+		if err != nil {
+			return nil, err
+		}
+		if j >= i {
+			return nil, fmt.Errorf("when %q must reference an earlier condition", c.When)
+		}
+		refs[j] = true
+	}
+	return refs, nil
+}
+
+func condIndex(ref string) (int, error) {
+	s, ok := strings.CutPrefix(ref, CondRefPrefix)
+	if !ok {
+		return 0, fmt.Errorf("when %q must be of the form %s<index>", ref, CondRefPrefix)
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("when %q must be of the form %s<index>", ref, CondRefPrefix)
+	}
+	return i, nil
+}
+
+// evalImplication evaluates c, or skips it as vacuously true when its When
+// antecedent, whose result is in results, did not match.
+func (e *Engine) evalImplication(ctx context.Context, st *evalState, c Condition, results []bool, data map[string]any) (bool, string, error) {
+	if c.When != "" {
+		if i, _ := condIndex(c.When); !results[i] {
+			return true, fmt.Sprintf("%s %s %v → true (%s not met)", e.fieldRef(c.Field), c.Op, e.describeValue(c), c.When), nil
+		}
+	}
+	return e.evalCondition(ctx, st, c, data)
+}
@@ -0,0 +1,78 @@
+package rules
+
+import "testing"
+
+func TestImplication(t *testing.T) {
+	// country == "US" implies age >= 21.
+	rule := Rule{Conditions: []Condition{
+		{Field: "country", Op: OperatorEQ, Value: "US"},
+		{Field: "age", Op: OperatorGTE, Value: 21, When: "$cond:0"},
+	}}
+	tests := []struct {
+		name string
+		data map[string]any
+		want bool
+	}{
+		{"antecedent and consequent", map[string]any{"country": "US", "age": 25}, true},
+		{"antecedent without consequent", map[string]any{"country": "US", "age": 19}, false},
+		{"vacuously true", map[string]any{"country": "DE", "age": 19}, true},
+		{"vacuous skips consequent field", map[string]any{"country": "DE"}, true},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Evaluate() = %v, want %v (%s)", res.Matched, tt.want, res.Explanation)
+			}
+		})
+	}
+}
+
+func TestImplicationWithOtherConditions(t *testing.T) {
+	rule := Rule{Conditions: []Condition{
+		{Field: "plan", Op: OperatorEQ, Value: "pro"},
+		{Field: "active", Op: OperatorEQ, Value: true},
+		{Field: "seats", Op: OperatorGTE, Value: 5, When: "$cond:0"},
+	}}
+	e := New()
+	res, err := e.Evaluate(rule, map[string]any{"plan": "free", "active": false, "seats": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Matched {
+		t.Error("unreferenced condition should still gate the rule")
+	}
+
+	rule.Logic = LogicOR
+	res, err = e.Evaluate(rule, map[string]any{"plan": "free", "active": false, "seats": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched || res.Explanation != "seats gte 5 → true ($cond:0 not met)" {
+		t.Errorf("Evaluate() = %+v, want vacuous match", res)
+	}
+}
+
+func TestImplicationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		when string
+	}{
+		{"forward reference", "$cond:1"},
+		{"self reference", "$cond:0"},
+		{"bad prefix", "cond:0"},
+		{"bad index", "$cond:x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "a", Op: OperatorEQ, Value: 1, When: tt.when}, {Field: "a", Op: OperatorEQ, Value: 1}}}
+			if _, err := New().Evaluate(rule, map[string]any{"a": 1}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// OperatorLen compares the length of a string, slice, array or map field
+// using a nested comparison, e.g. {"op":"lte","value":150} or, for a list
+// of selected items, {"op":"gte","value":1}. Collections count their
+// elements. For strings, length by default counts runes;
+// a "mode" of "bytes" counts UTF-8 bytes and "graphemes" counts
+// user-perceived characters, so an emoji with skin tone, a flag or a letter
+// with combining accents each count once:
+//
+//	{"field":"bio","op":"len","value":{"op":"lte","value":150,"mode":"graphemes"}}
+//
+// Grapheme counting covers combining marks, zero-width joiner sequences,
+// emoji modifiers, regional indicator pairs, tag sequences and CRLF; it is
+// an approximation of Unicode text segmentation (UAX #29) that does not
+// handle, for example, Hangul syllable composition.
+const OperatorLen Operator = "len"
+
+// Length modes accepted by OperatorLen.
+const (
+	LenRunes     = "runes"
+	LenBytes     = "bytes"
+	LenGraphemes = "graphemes"
+)
+
+func (e *Engine) length(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	v, want, err := e.operands(ctx, st, c, data)
+	if err != nil {
+		return false, err
+	}
+	spec, _ := want.(map[string]any)
+	mode, _ := spec["mode"].(string)
+	n, err := lengthOf(v, mode)
+	if err != nil {
+		return false, err
+	}
+	return e.applySpec(ctx, st, OperatorLen, want, n, data)
+}
+
+// lengthOf measures v, in the given mode for strings.
+func lengthOf(v any, mode string) (int, error) {
+	s, ok := v.(string)
+	if !ok {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if mode != "" {
+				return 0, fmt.Errorf("len mode %q applies only to strings", mode)
+			}
+			return rv.Len(), nil
+		}
+		return 0, typeMismatch("len", v)
+	}
+	switch mode {
+	case "", LenRunes:
+		return utf8.RuneCountInString(s), nil
+	case LenBytes:
+		return len(s), nil
+	case LenGraphemes:
+		return graphemeCount(s), nil
+	}
+	return 0, fmt.Errorf("unknown len mode %q", mode)
+}
+
+// graphemeCount counts approximate grapheme clusters in s.
+func graphemeCount(s string) int {
+	n := 0
+	prev := rune(-1)
+	joined := false // previous rune was a zero-width joiner
+	regional := 0   // regional indicators in the current run
+	for _, r := range s {
+		switch {
+		case prev == '\r' && r == '\n':
+		case prev >= 0 && extendsCluster(r):
+		case joined:
+		case isRegionalIndicator(r) && regional%2 == 1:
+		default:
+			n++
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		joined = r == '\u200d'
+		prev = r
+	}
+	return n
+}
+
+// extendsCluster reports whether r attaches to the preceding character.
+func extendsCluster(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r == '\u200d': // zero-width joiner
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // emoji skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tags
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
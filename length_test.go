@@ -0,0 +1,101 @@
+package rules
+
+import "testing"
+
+func TestLenModes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		mode string
+		want int
+	}{
+		{"ascii runes", "hello", "", 5},
+		{"ascii graphemes", "hello", LenGraphemes, 5},
+		{"accented bytes", "caf\u00e9", LenBytes, 5},
+		{"combining accent runes", "cafe\u0301", LenRunes, 5},
+		{"combining accent graphemes", "cafe\u0301", LenGraphemes, 4},
+		{"stacked combining marks", "a\u0308\u0301b", LenGraphemes, 2},
+		{"skin tone emoji", "\U0001f44d\U0001f3fd", LenGraphemes, 1},
+		{"zwj family", "\U0001f468\u200d\U0001f469\u200d\U0001f467\u200d\U0001f466", LenGraphemes, 1},
+		{"zwj family runes", "\U0001f468\u200d\U0001f469\u200d\U0001f467\u200d\U0001f466", LenRunes, 7},
+		{"flags", "\U0001f1eb\U0001f1f7\U0001f1e9\U0001f1ea", LenGraphemes, 2},
+		{"odd regional indicators", "\U0001f1eb\U0001f1f7\U0001f1e9", LenGraphemes, 2},
+		{"variation selector", "\u2764\ufe0f!", LenGraphemes, 2},
+		{"crlf", "a\r\nb", LenGraphemes, 3},
+		{"leading combining mark", "\u0301a", LenGraphemes, 2},
+		{"empty", "", LenGraphemes, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lengthOf(tt.s, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("lengthOf(%q, %q) = %d, want %d", tt.s, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLenOperator(t *testing.T) {
+	tests := []struct {
+		name    string
+		bio     any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "within limit", bio: "hi \U0001f44b\U0001f3fe", value: map[string]any{"op": "lte", "value": 4, "mode": "graphemes"}, want: true},
+		{name: "rune count exceeds", bio: "hi \U0001f44b\U0001f3fe", value: map[string]any{"op": "lte", "value": 4}, want: false},
+		{name: "byte count", bio: "h\u00e9", value: map[string]any{"op": "eq", "value": 3, "mode": "bytes"}, want: true},
+		{name: "unknown mode", bio: "x", value: map[string]any{"op": "lte", "value": 4, "mode": "words"}, wantErr: true},
+		{name: "missing comparison", bio: "x", value: 4, wantErr: true},
+		{name: "not a string", bio: 42, value: map[string]any{"op": "lte", "value": 4}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "bio", Op: OperatorLen, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"bio": tt.bio})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestLenCollections(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "password long enough", field: "correct horse", value: map[string]any{"op": "gte", "value": 8}, want: true},
+		{name: "password too short", field: "h\u00e9llo", value: map[string]any{"op": "gte", "value": 8}, want: false},
+		{name: "selected items minimum", field: []any{"a", "b"}, value: map[string]any{"op": "gte", "value": 2}, want: true},
+		{name: "empty slice", field: []any{}, value: map[string]any{"op": "gt", "value": 0}, want: false},
+		{name: "typed slice", field: []string{"a", "b", "c"}, value: map[string]any{"op": "eq", "value": 3}, want: true},
+		{name: "array", field: [2]int{1, 2}, value: map[string]any{"op": "eq", "value": 2}, want: true},
+		{name: "map", field: map[string]any{"a": 1}, value: map[string]any{"op": "lt", "value": 2}, want: true},
+		{name: "mode on slice", field: []any{"a"}, value: map[string]any{"op": "eq", "value": 1, "mode": "bytes"}, wantErr: true},
+		{name: "number", field: 42, value: map[string]any{"op": "eq", "value": 2}, wantErr: true},
+		{name: "bool", field: true, value: map[string]any{"op": "eq", "value": 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "f", Op: OperatorLen, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"f": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OperatorKeysEqual matches when a map field has exactly the keys listed in
+// the value slice, in any order. Keys are compared as they appear in field
+// paths, so an int-keyed map has key "1".
+const OperatorKeysEqual Operator = "keys_equal"
+
+func keysEqual(a, b any) (bool, error) {
+	keys, ok := mapKeys(a)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for keys_equal")
+	}
+	names, ok := b.([]any)
+	if !ok {
+		return false, fmt.Errorf("keys_equal requires slice value")
+	}
+	want := make(map[string]struct{}, len(names))
+	for _, n := range names {
+		want[fmt.Sprint(n)] = struct{}{}
+	}
+	if len(want) != len(keys) {
+		return false, nil
+	}
+	for _, k := range keys {
+		if _, ok := want[k]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// mapKeys returns the keys of a map as path segments.
+func mapKeys(v any) ([]string, bool) {
+	if m, ok := v.(map[string]any); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		return keys, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	keys := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, fmt.Sprint(k.Interface()))
+	}
+	return keys, true
+}
@@ -0,0 +1,36 @@
+package rules
+
+import "testing"
+
+func TestKeysEqual(t *testing.T) {
+	value := []any{"a", "b", "c"}
+	tests := []struct {
+		name    string
+		meta    any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "exact keys", meta: map[string]any{"c": 1, "a": 2, "b": 3}, value: value, want: true},
+		{name: "missing key", meta: map[string]any{"a": 1, "b": 2}, value: value, want: false},
+		{name: "extra key", meta: map[string]any{"a": 1, "b": 2, "c": 3, "d": 4}, value: value, want: false},
+		{name: "different key", meta: map[string]any{"a": 1, "b": 2, "x": 3}, value: value, want: false},
+		{name: "duplicate names", meta: map[string]any{"a": 1}, value: []any{"a", "a"}, want: true},
+		{name: "empty", meta: map[string]any{}, value: []any{}, want: true},
+		{name: "typed map", meta: map[int]string{1: "x", 2: "y"}, value: []any{"2", 1.0}, want: true},
+		{name: "not a map", meta: []any{"a"}, value: value, wantErr: true},
+		{name: "malformed value", meta: map[string]any{"a": 1}, value: "a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "metadata", Op: OperatorKeysEqual, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"metadata": tt.meta})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
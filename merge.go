@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// EvaluateMerged evaluates rule against the merge of sources, such as
+// defaults, tenant settings and request data. Later sources take
+// precedence, and nested maps are merged key by key. It also returns the
+// sorted paths used by the rule that at least two sources set to different
+// values, so callers can flag ambiguous inputs; a path missing from a source
+// does not conflict. Conflicts are not errors: the rule still sees the
+// value of the latest source.
+func (e *Engine) EvaluateMerged(ctx context.Context, rule Rule, sources ...map[string]any) (Result, []string, error) {
+	var merged map[string]any
+	for _, src := range sources {
+		merged = mergeMaps(merged, src)
+	}
+	var conflicts []string
+	for _, path := range ruleFields(rule) {
+		var first any
+		seen := false
+		for _, src := range sources {
+			v, ok := getValue(src, path)
+			if !ok {
+				continue
+			}
+			if seen && !equal(first, v) {
+				conflicts = append(conflicts, path)
+				break
+			}
+			first, seen = v, true
+		}
+	}
+	res, err := e.EvaluateWithContext(ctx, rule, merged)
+	if err != nil {
+		return Result{}, nil, err
+	}
+	return res, conflicts, nil
+}
+
+// mergeMaps returns dst overlaid with src, merging nested maps key by key.
+// Neither argument is modified.
+func mergeMaps(dst, src map[string]any) map[string]any {
+	out := maps.Clone(dst)
+	if out == nil {
+		out = make(map[string]any, len(src))
+	}
+	for k, v := range src {
+		if sub, ok := v.(map[string]any); ok {
+			if prev, ok := out[k].(map[string]any); ok {
+				out[k] = mergeMaps(prev, sub)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// ruleFields returns the sorted field paths a rule's conditions read:
+// fields, value fields, "$field:" values and discriminators with their
+// paths.
+func ruleFields(rule Rule) []string {
+	conds := rule.Conditions
+	if rule.Root != nil {
+		conds = rule.Root.conditions(slices.Clone(conds))
+	}
+	var paths []string
+	for _, c := range conds {
+		paths = append(paths, c.Field, c.ValueField)
+		if s, ok := c.Value.(string); ok && strings.HasPrefix(s, fieldRefPrefix) {
+			paths = append(paths, strings.TrimPrefix(s, fieldRefPrefix))
+		}
+		if c.FieldBy != nil {
+			paths = append(paths, c.FieldBy.Discriminator, c.FieldBy.Default)
+			for _, p := range c.FieldBy.Paths {
+				paths = append(paths, p)
+			}
+		}
+	}
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+	if len(paths) > 0 && paths[0] == "" {
+		paths = paths[1:]
+	}
+	return paths
+}
@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateMerged(t *testing.T) {
+	rule := Rule{Conditions: []Condition{
+		{Field: "plan.tier", Op: OperatorEQ, Value: "pro"},
+		{Field: "usage", Op: OperatorLT, ValueField: "limit"},
+	}}
+	defaults := map[string]any{"plan": map[string]any{"tier": "free", "seats": 1}, "limit": 100}
+	tests := []struct {
+		name          string
+		sources       []map[string]any
+		want          bool
+		wantConflicts []string
+	}{
+		{
+			name:    "disjoint sources",
+			sources: []map[string]any{{"plan": map[string]any{"tier": "pro"}, "limit": 100}, {"usage": 50}},
+			want:    true,
+		},
+		{
+			name:    "agreeing sources",
+			sources: []map[string]any{{"plan": map[string]any{"tier": "pro"}, "limit": 100}, {"limit": 100.0, "usage": 50}},
+			want:    true,
+		},
+		{
+			name:          "later source wins and conflicts",
+			sources:       []map[string]any{defaults, {"plan": map[string]any{"tier": "pro"}, "usage": 50}},
+			want:          true,
+			wantConflicts: []string{"plan.tier"},
+		},
+		{
+			name:          "conflicts on several paths",
+			sources:       []map[string]any{defaults, {"plan": map[string]any{"tier": "pro"}, "limit": 10, "usage": 50}},
+			want:          false,
+			wantConflicts: []string{"limit", "plan.tier"},
+		},
+		{
+			name:    "conflict on unused key ignored",
+			sources: []map[string]any{defaults, {"plan": map[string]any{"tier": "free", "seats": 5}, "usage": 50}},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, conflicts, err := New().EvaluateMerged(context.Background(), rule, tt.sources...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v (%s)", res.Matched, tt.want, res.Explanation)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+	if defaults["plan"].(map[string]any)["tier"] != "free" {
+		t.Error("EvaluateMerged modified a source")
+	}
+}
@@ -0,0 +1,42 @@
+package rules
+
+// OpenFeature resolution reasons reported in FlagDetails.
+const (
+	ReasonTargetingMatch = "TARGETING_MATCH"
+	ReasonDefault        = "DEFAULT"
+	ReasonError          = "ERROR"
+)
+
+// FlagDetails mirrors the evaluation details an OpenFeature provider
+// returns for a resolved flag.
+type FlagDetails struct {
+	Value   any    `json:"value"`
+	Variant string `json:"variant,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// FlagDetailsFromResult maps a rule result onto flag details. A match
+// resolves to variant and value with reason TARGETING_MATCH; otherwise
+// defaultValue is returned with reason DEFAULT.
+func FlagDetailsFromResult(res Result, variant string, value, defaultValue any) FlagDetails {
+	if !res.Matched {
+		return FlagDetails{Value: defaultValue, Reason: ReasonDefault}
+	}
+	return FlagDetails{Value: value, Variant: variant, Reason: ReasonTargetingMatch}
+}
+
+// FlagDetailsFromFirstMatch maps the outcome of a first-match evaluation over
+// named rules onto flag details. matched is the name of the rule that matched,
+// or empty when none did, and is used as the variant whose value is looked up
+// in variants. A matched name without a variant value resolves to
+// defaultValue with reason ERROR.
+func FlagDetailsFromFirstMatch(matched string, variants map[string]any, defaultValue any) FlagDetails {
+	if matched == "" {
+		return FlagDetails{Value: defaultValue, Reason: ReasonDefault}
+	}
+	value, ok := variants[matched]
+	if !ok {
+		return FlagDetails{Value: defaultValue, Reason: ReasonError}
+	}
+	return FlagDetails{Value: value, Variant: matched, Reason: ReasonTargetingMatch}
+}
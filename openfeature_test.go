@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlagDetailsFromResult(t *testing.T) {
+	tests := []struct {
+		name string
+		res  Result
+		want FlagDetails
+	}{
+		{
+			name: "matched",
+			res:  Result{Matched: true},
+			want: FlagDetails{Value: true, Variant: "on", Reason: ReasonTargetingMatch},
+		},
+		{
+			name: "unmatched",
+			res:  Result{Matched: false},
+			want: FlagDetails{Value: false, Reason: ReasonDefault},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FlagDetailsFromResult(tt.res, "on", true, false)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagDetailsFromFirstMatch(t *testing.T) {
+	variants := map[string]any{"beta": "new-checkout", "control": "old-checkout"}
+	tests := []struct {
+		name    string
+		matched string
+		want    FlagDetails
+	}{
+		{name: "matched", matched: "beta", want: FlagDetails{Value: "new-checkout", Variant: "beta", Reason: ReasonTargetingMatch}},
+		{name: "no match", matched: "", want: FlagDetails{Value: "old-checkout", Reason: ReasonDefault}},
+		{name: "unknown variant", matched: "gamma", want: FlagDetails{Value: "old-checkout", Reason: ReasonError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FlagDetailsFromFirstMatch(tt.matched, variants, "old-checkout")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
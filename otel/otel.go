@@ -0,0 +1,57 @@
+// Package otel adapts OpenTelemetry tracers for rules.Engine.Tracer. It is a
+// separate module so that the rules package has no dependencies.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	rules "github.com/njchilds90/go-rules"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracer adapts an OpenTelemetry tracer for Engine.Tracer; a nil tracer
+// yields a nil Tracer, recording no spans:
+//
+//	e.Tracer = rulesotel.NewTracer(otel.Tracer("rules"))
+func NewTracer(t trace.Tracer) rules.Tracer {
+	if t == nil {
+		return nil
+	}
+	return otelTracer{t}
+}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t otelTracer) Start(ctx context.Context, name string) (context.Context, rules.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key string, value any) {
+	switch v := value.(type) {
+	case string:
+		s.span.SetAttributes(attribute.String(key, v))
+	case bool:
+		s.span.SetAttributes(attribute.Bool(key, v))
+	case float64:
+		s.span.SetAttributes(attribute.Float64(key, v))
+	default:
+		s.span.SetAttributes(attribute.String(key, fmt.Sprint(v)))
+	}
+}
+
+func (s otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s otelSpan) End() { s.span.End() }
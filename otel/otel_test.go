@@ -0,0 +1,41 @@
+package otel
+
+import (
+	"testing"
+
+	rules "github.com/njchilds90/go-rules"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	e := rules.New()
+	e.Tracer = NewTracer(provider.Tracer("rules"))
+
+	rule := rules.Rule{Conditions: []rules.Condition{{Field: "age", Op: rules.OperatorGTE, Value: 18}}}
+	if _, err := e.Evaluate(rule, map[string]any{"age": 30}); err != nil {
+		t.Fatal(err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != rules.SpanName {
+		t.Fatalf("got %d spans, want one %q span", len(spans), rules.SpanName)
+	}
+	attrs := map[string]bool{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[string(kv.Key)] = true
+		if string(kv.Key) == rules.AttrRuleMatched && !kv.Value.AsBool() {
+			t.Errorf("%s = false, want true", rules.AttrRuleMatched)
+		}
+	}
+	for _, key := range []string{rules.AttrRuleHash, rules.AttrRuleMatched, rules.AttrRuleDuration} {
+		if !attrs[key] {
+			t.Errorf("span missing attribute %s", key)
+		}
+	}
+
+	if NewTracer(nil) != nil {
+		t.Error("NewTracer(nil) should be nil")
+	}
+}
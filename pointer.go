@@ -0,0 +1,49 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldRef renders a dot path for explanations and errors, as a JSON Pointer
+// when JSONPointer is set.
+func (e *Engine) fieldRef(path string) string {
+	if !e.JSONPointer {
+		return path
+	}
+	return jsonPointer(path)
+}
+
+// fieldNotFound reports a missing field referenced by path.
+func (e *Engine) fieldNotFound(path string) error {
+	return &missingFieldError{ref: e.fieldRef(path)}
+}
+
+// missingFieldError is the ErrFieldNotFound error for one field, keeping its
+// reference for explanations.
+type missingFieldError struct {
+	ref string
+}
+
+func (err *missingFieldError) Error() string {
+	return fmt.Sprintf("field %q not found: %v", err.ref, ErrFieldNotFound)
+}
+
+func (err *missingFieldError) Unwrap() error { return ErrFieldNotFound }
+
+// jsonPointerEscaper escapes reference tokens per RFC 6901.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointer converts a dot path such as "user.age" to the JSON Pointer
+// "/user/age". The empty path is the whole document, "".
+func jsonPointer(path string) string {
+	if path == "" {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		b.WriteByte('/')
+		b.WriteString(jsonPointerEscaper.Replace(part))
+	}
+	return b.String()
+}
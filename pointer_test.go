@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONPointerExplanations(t *testing.T) {
+	data := map[string]any{"user": map[string]any{"age": 30, "limit": 40, "a/b": "x"}}
+	tests := []struct {
+		name    string
+		pointer bool
+		cond    Condition
+		want    string
+	}{
+		{"dot path", false, Condition{Field: "user.age", Op: OperatorGT, Value: 18}, "user.age gt 18 → true"},
+		{"pointer", true, Condition{Field: "user.age", Op: OperatorGT, Value: 18}, "/user/age gt 18 → true"},
+		{"dot path value field", false, Condition{Field: "user.age", Op: OperatorLT, ValueField: "user.limit"}, "user.age lt $field:user.limit → true"},
+		{"pointer value field", true, Condition{Field: "user.age", Op: OperatorLT, ValueField: "user.limit"}, "/user/age lt $field:/user/limit → true"},
+		{"pointer escapes", true, Condition{Field: "user.a/b", Op: OperatorEQ, Value: "x"}, "/user/a~1b eq x → true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			e.JSONPointer = tt.pointer
+			res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}, Logic: LogicOR}, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Explanation != tt.want {
+				t.Errorf("Explanation = %q, want %q", res.Explanation, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPointerNotFound(t *testing.T) {
+	e := New()
+	e.JSONPointer = true
+	_, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "user.name", Op: OperatorEQ, Value: "x"}}}, map[string]any{})
+	if err == nil || !strings.Contains(err.Error(), `"/user/name"`) {
+		t.Errorf("error = %v, want pointer reference", err)
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"age":      "/age",
+		"user.age": "/user/age",
+		"a~b.c/d":  "/a~0b/c~1d",
+	}
+	for path, want := range tests {
+		if got := jsonPointer(path); got != want {
+			t.Errorf("jsonPointer(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// OperatorIsPort matches a field holding a whole number within a port
+// range, 1 to 65535 by default. Numeric strings such as "8080" are
+// accepted, as configuration often carries ports as text. A [min, max]
+// value narrows the range, e.g. [1024, 49151] for registered ports; its
+// bounds are inclusive and must themselves be ports or 0.
+const OperatorIsPort Operator = "is_port"
+
+func isPort(a, b any) (bool, error) {
+	lo, hi, err := portRange(b)
+	if err != nil {
+		return false, err
+	}
+	var n float64
+	switch x := a.(type) {
+	case string:
+		i, err := strconv.Atoi(x)
+		if err != nil {
+			return false, nil
+		}
+		n = float64(i)
+	default:
+		f, ok := toFloat(a)
+		if !ok {
+			return false, fmt.Errorf("type mismatch for is_port")
+		}
+		n = f
+	}
+	return n == math.Trunc(n) && n >= lo && n <= hi, nil
+}
+
+// portRange reads the optional [min, max] value of OperatorIsPort.
+func portRange(b any) (lo, hi float64, err error) {
+	if b == nil {
+		return 1, 65535, nil
+	}
+	bounds, ok := b.([]any)
+	if !ok || len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("is_port requires [min, max] value")
+	}
+	var r [2]float64
+	for i, bound := range bounds {
+		f, ok := toFloat(bound)
+		if !ok || f != math.Trunc(f) || f < 0 || f > 65535 {
+			return 0, 0, fmt.Errorf("is_port bounds must be whole numbers from 0 to 65535, got %v", bound)
+		}
+		r[i] = f
+	}
+	if r[0] > r[1] {
+		return 0, 0, fmt.Errorf("is_port range [%v, %v] is empty", r[0], r[1])
+	}
+	return r[0], r[1], nil
+}
@@ -0,0 +1,43 @@
+package rules
+
+import "testing"
+
+func TestIsPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid port", port: 8080, want: true},
+		{name: "lowest port", port: 1, want: true},
+		{name: "highest port", port: 65535, want: true},
+		{name: "float from JSON", port: 443.0, want: true},
+		{name: "numeric string", port: "5432", want: true},
+		{name: "zero", port: 0, want: false},
+		{name: "negative", port: -1, want: false},
+		{name: "out of range", port: 65536, want: false},
+		{name: "fractional", port: 80.5, want: false},
+		{name: "non-numeric string", port: "http", want: false},
+		{name: "within custom range", port: 8080, value: []any{1024, 49151}, want: true},
+		{name: "below custom range", port: 80, value: []any{1024, 49151}, want: false},
+		{name: "zero allowed by range", port: 0, value: []any{0, 1023}, want: true},
+		{name: "wrong type", port: true, wantErr: true},
+		{name: "malformed range", port: 80, value: []any{1}, wantErr: true},
+		{name: "range beyond ports", port: 80, value: []any{1, 70000}, wantErr: true},
+		{name: "empty range", port: 80, value: []any{100, 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "port", Op: OperatorIsPort, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"port": tt.port})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CounterRefKey is the key of a condition value object that refers to a
+// counter, e.g. {"$counter": "requests:user123"}.
+const CounterRefKey = "$counter"
+
+// CounterProvider supplies the current value of named counters for
+// {"$counter": key} condition values.
+type CounterProvider interface {
+	Count(ctx context.Context, key string) (float64, error)
+}
+
+// count returns the current value of the counter key.
+func (e *Engine) count(ctx context.Context, key string) (float64, error) {
+	if e.Counters == nil {
+		return 0, fmt.Errorf("counter %q referenced but no CounterProvider is configured", key)
+	}
+	n, err := e.Counters.Count(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("counter %q: %w", key, err)
+	}
+	return n, nil
+}
+
+// WindowRefPrefix marks a reference to a windowed aggregate, written
+// "$window:<aggregate>:<duration>" or "$window:<aggregate>:<duration>:<metric>",
+// e.g. "$window:avg:5m:latency". It may be used as a condition's Field or
+// Value and is resolved through Engine.Windows.
+const WindowRefPrefix = "$window:"
+
+// WindowSpec describes the windowed aggregate a $window reference asks for.
+type WindowSpec struct {
+	Aggregate string        // e.g. "avg", "sum", "p95"; interpreted by the provider
+	Window    time.Duration // how far back the window reaches
+	Metric    string        // optional metric name
+}
+
+// parseWindowRef parses a $window reference.
+func parseWindowRef(ref string) (WindowSpec, error) {
+	parts := strings.Split(strings.TrimPrefix(ref, WindowRefPrefix), ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return WindowSpec{}, fmt.Errorf("invalid window reference %q: want $window:<aggregate>:<duration>[:<metric>]", ref)
+	}
+	d, err := time.ParseDuration(parts[1])
+	if err != nil || d <= 0 {
+		return WindowSpec{}, fmt.Errorf("invalid window reference %q: bad duration %q", ref, parts[1])
+	}
+	spec := WindowSpec{Aggregate: parts[0], Window: d}
+	if len(parts) == 3 {
+		spec.Metric = parts[2]
+	}
+	return spec, nil
+}
+
+// window resolves a $window reference through the engine's Windows callback.
+func (e *Engine) window(ctx context.Context, ref string) (float64, error) {
+	spec, err := parseWindowRef(ref)
+	if err != nil {
+		return 0, err
+	}
+	if e.Windows == nil {
+		return 0, fmt.Errorf("window %q referenced but Engine.Windows is not set", ref)
+	}
+	v, err := e.Windows(ctx, spec)
+	if err != nil {
+		return 0, fmt.Errorf("window %q: %w", ref, err)
+	}
+	return v, nil
+}
+
+// SetRefPrefix marks a condition value naming a set supplied by
+// Engine.Sets, e.g. {"op":"in","value":"$set:allowed_countries"}.
+const SetRefPrefix = "$set:"
+
+// SetProvider supplies named sets, such as allowlists fetched from a remote
+// source, for "$set:" condition values.
+type SetProvider interface {
+	Set(ctx context.Context, name string) ([]any, error)
+}
+
+// set resolves a "$set:" reference through the engine's SetProvider.
+func (e *Engine) set(ctx context.Context, ref string) ([]any, error) {
+	name := strings.TrimPrefix(ref, SetRefPrefix)
+	if e.Sets == nil {
+		return nil, fmt.Errorf("set %q referenced but no SetProvider is configured", name)
+	}
+	items, err := e.Sets.Set(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("set %q: %w", name, err)
+	}
+	return items, nil
+}
+
+// CachedSetProvider wraps a SetProvider, caching each set for TTL. Errors are
+// not cached. It is safe for concurrent use.
+type CachedSetProvider struct {
+	Provider SetProvider
+	TTL      time.Duration
+	// Now is the clock used for expiry; nil means time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedSet
+}
+
+type cachedSet struct {
+	items   []any
+	expires time.Time
+}
+
+// NewCachedSetProvider returns a CachedSetProvider caching p's sets for ttl.
+func NewCachedSetProvider(p SetProvider, ttl time.Duration) *CachedSetProvider {
+	return &CachedSetProvider{Provider: p, TTL: ttl}
+}
+
+// Set returns the named set from the cache, fetching it from the wrapped
+// provider when absent or expired.
+func (c *CachedSetProvider) Set(ctx context.Context, name string) ([]any, error) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok && now().Before(entry.expires) {
+		return entry.items, nil
+	}
+	items, err := c.Provider.Set(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedSet)
+	}
+	c.entries[name] = cachedSet{items: items, expires: now().Add(c.TTL)}
+	c.mu.Unlock()
+	return items, nil
+}
@@ -0,0 +1,162 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeCounters map[string]float64
+
+func (f fakeCounters) Count(_ context.Context, key string) (float64, error) {
+	n, ok := f[key]
+	if !ok {
+		return 0, errors.New("no such counter")
+	}
+	return n, nil
+}
+
+func TestCounterReference(t *testing.T) {
+	counters := fakeCounters{"requests:user123": 0}
+	e := New()
+	e.Counters = counters
+	rule := Rule{Conditions: []Condition{{Field: "limit", Op: OperatorGTE, Value: map[string]any{CounterRefKey: "requests:user123"}}}}
+	data := map[string]any{"limit": 100}
+	for _, tt := range []struct {
+		count float64
+		want  bool
+	}{
+		{count: 0, want: true},
+		{count: 99, want: true},
+		{count: 100, want: true},
+		{count: 101, want: false},
+	} {
+		counters["requests:user123"] = tt.count
+		res, err := e.Evaluate(rule, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != tt.want {
+			t.Errorf("count %v: Matched = %v, want %v", tt.count, res.Matched, tt.want)
+		}
+	}
+
+	missing := Rule{Conditions: []Condition{{Field: "limit", Op: OperatorGTE, Value: map[string]any{CounterRefKey: "requests:other"}}}}
+	if _, err := e.Evaluate(missing, data); err == nil {
+		t.Error("expected provider error to propagate")
+	}
+	if _, err := New().Evaluate(rule, data); err == nil {
+		t.Error("expected error without a CounterProvider")
+	}
+}
+
+func TestWindowReference(t *testing.T) {
+	var got []WindowSpec
+	e := New()
+	e.Windows = func(_ context.Context, spec WindowSpec) (float64, error) {
+		got = append(got, spec)
+		switch {
+		case spec.Aggregate == "avg" && spec.Window == 5*time.Minute:
+			return 250, nil
+		case spec.Aggregate == "avg" && spec.Window == time.Hour:
+			return 180, nil
+		}
+		return 0, errors.New("unsupported window")
+	}
+	tests := []struct {
+		name    string
+		cond    Condition
+		want    bool
+		wantErr bool
+	}{
+		{name: "field above threshold", cond: Condition{Field: "$window:avg:5m:latency", Op: OperatorGT, Value: 200}, want: true},
+		{name: "field below threshold", cond: Condition{Field: "$window:avg:1h", Op: OperatorGT, Value: 200}, want: false},
+		{name: "window as value", cond: Condition{Field: "latency", Op: OperatorGT, Value: "$window:avg:1h"}, want: true},
+		{name: "provider error", cond: Condition{Field: "$window:max:5m", Op: OperatorGT, Value: 1}, wantErr: true},
+		{name: "malformed reference", cond: Condition{Field: "$window:avg", Op: OperatorGT, Value: 1}, wantErr: true},
+		{name: "bad duration", cond: Condition{Field: "$window:avg:soon", Op: OperatorGT, Value: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, map[string]any{"latency": 300})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+	if want := (WindowSpec{Aggregate: "avg", Window: 5 * time.Minute, Metric: "latency"}); got[0] != want {
+		t.Errorf("provider got spec %+v, want %+v", got[0], want)
+	}
+
+	if _, err := New().Evaluate(Rule{Conditions: []Condition{{Field: "$window:avg:5m", Op: OperatorGT, Value: 1}}}, nil); err == nil {
+		t.Error("expected error without a window provider")
+	}
+}
+
+type countingSets struct {
+	calls int
+	sets  map[string][]any
+}
+
+func (c *countingSets) Set(_ context.Context, name string) ([]any, error) {
+	c.calls++
+	items, ok := c.sets[name]
+	if !ok {
+		return nil, errors.New("no such set")
+	}
+	return items, nil
+}
+
+func TestCachedSetProvider(t *testing.T) {
+	source := &countingSets{sets: map[string][]any{"countries": {"US", "CA"}}}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cached := NewCachedSetProvider(source, time.Minute)
+	cached.Now = func() time.Time { return now }
+
+	e := New()
+	e.Sets = cached
+	rule := Rule{Conditions: []Condition{{Field: "country", Op: OperatorIn, Value: "$set:countries"}}}
+	check := func(country string, want bool) {
+		t.Helper()
+		res, err := e.Evaluate(rule, map[string]any{"country": country})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != want {
+			t.Errorf("country %s: Matched = %v, want %v", country, res.Matched, want)
+		}
+	}
+
+	check("US", true)
+	check("FR", false)
+	if source.calls != 1 {
+		t.Errorf("calls = %d, want 1 within TTL", source.calls)
+	}
+
+	source.sets["countries"] = []any{"FR"}
+	now = now.Add(59 * time.Second)
+	check("FR", false)
+	if source.calls != 1 {
+		t.Errorf("calls = %d, want cached set before expiry", source.calls)
+	}
+
+	now = now.Add(time.Second)
+	check("FR", true)
+	if source.calls != 2 {
+		t.Errorf("calls = %d, want refetch after expiry", source.calls)
+	}
+
+	missing := Rule{Conditions: []Condition{{Field: "country", Op: OperatorIn, Value: "$set:regions"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := e.Evaluate(missing, map[string]any{"country": "US"}); err == nil {
+			t.Error("expected provider error")
+		}
+	}
+	if source.calls != 4 {
+		t.Errorf("calls = %d, errors should not be cached", source.calls)
+	}
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// OperatorRegex matches a field against the regular expression in the
+// condition value. Numbers and booleans are matched in their string form.
+// The pattern may embed other fields' values as {{path}}, e.g.
+// "^{{customer_id}}-"; embedded values are quoted, so they match literally.
+// Patterns without embedded fields are compiled once per engine and cached,
+// up to a bound on the number of patterns cached.
+const OperatorRegex Operator = "regex"
+
+// fieldPlaceholder matches a {{path}} field reference in a regex pattern.
+var fieldPlaceholder = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+func (e *Engine) regex(_ context.Context, a, b any, data map[string]any) (bool, error) {
+	s, ok := regexSubject(a)
+	if !ok {
+		return false, typeMismatch("regex", a)
+	}
+	pattern, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("regex requires string pattern value")
+	}
+	if !fieldPlaceholder.MatchString(pattern) {
+		re, err := e.compileCached(pattern)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(s), nil
+	}
+	pattern, err := e.embedFields(pattern, data)
+	if err != nil {
+		return false, err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return re.MatchString(s), nil
+}
+
+// regexSubject returns the string a regex is matched against: strings as
+// they are, and numbers and booleans in their string form.
+func regexSubject(a any) (string, bool) {
+	switch x := a.(type) {
+	case string:
+		return x, true
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(x), true
+	}
+	return "", false
+}
+
+// OperatorMatchesAtLeast matches a field matching at least k of several
+// regular expressions, for heuristic classification:
+// {"field":"subject","op":"matches_at_least","value":{"patterns":["(?i)free","!{2,}","\\$\\d+"],"k":2}}.
+// Patterns are compiled once per engine and cached, and k must lie between 1
+// and the number of patterns.
+const OperatorMatchesAtLeast Operator = "matches_at_least"
+
+func (e *Engine) matchesAtLeast(a, b any) (bool, error) {
+	s, ok := regexSubject(a)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for matches_at_least")
+	}
+	patterns, k, err := matchesAtLeastParams(b)
+	if err != nil {
+		return false, err
+	}
+	n := 0
+	for _, p := range patterns {
+		if n == k {
+			break
+		}
+		re, err := e.compileCached(p)
+		if err != nil {
+			return false, err
+		}
+		if re.MatchString(s) {
+			n++
+		}
+	}
+	return n >= k, nil
+}
+
+// matchesAtLeastParams reads the {"patterns":[...],"k":N} value of
+// OperatorMatchesAtLeast.
+func matchesAtLeastParams(b any) (patterns []string, k int, err error) {
+	spec, ok := b.(map[string]any)
+	if !ok {
+		return nil, 0, fmt.Errorf(`matches_at_least requires {"patterns":[...],"k":N} value`)
+	}
+	list, ok := spec["patterns"].([]any)
+	if !ok || len(list) == 0 {
+		return nil, 0, fmt.Errorf("matches_at_least requires a non-empty patterns list")
+	}
+	for _, p := range list {
+		s, ok := p.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("matches_at_least patterns must be strings, got %v", p)
+		}
+		patterns = append(patterns, s)
+	}
+	f, ok := toFloat(spec["k"])
+	if !ok || f != math.Trunc(f) || f < 1 || int(f) > len(patterns) {
+		return nil, 0, fmt.Errorf("matches_at_least k must be a whole number from 1 to %d", len(patterns))
+	}
+	return patterns, int(f), nil
+}
+
+// maxCachedRegexps bounds the regex cache, since patterns may come from the
+// data through ValueField or "$field:" references.
+const maxCachedRegexps = 1024
+
+// compileCached compiles pattern, reusing an earlier compilation. Invalid
+// patterns are not cached, and once maxCachedRegexps patterns are cached
+// new ones are compiled each time.
+func (e *Engine) compileCached(pattern string) (*regexp.Regexp, error) {
+	if re, ok := e.regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	if e.regexpN.Add(1) > maxCachedRegexps {
+		e.regexpN.Add(-1)
+		return re, nil
+	}
+	if _, loaded := e.regexps.LoadOrStore(pattern, re); loaded {
+		e.regexpN.Add(-1)
+	}
+	return re, nil
+}
+
+// embedFields replaces each {{path}} in pattern with the quoted value of the
+// referenced field.
+func (e *Engine) embedFields(pattern string, data map[string]any) (string, error) {
+	var firstErr error
+	out := fieldPlaceholder.ReplaceAllStringFunc(pattern, func(m string) string {
+		v, err := e.field(data, fieldPlaceholder.FindStringSubmatch(m)[1])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return m
+		}
+		return regexp.QuoteMeta(fmt.Sprint(v))
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
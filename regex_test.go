@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRegexFieldReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		data    map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{name: "matching prefix", pattern: "^{{customer_id}}-", data: map[string]any{"order_id": "C42-0001", "customer_id": "C42"}, want: true},
+		{name: "other customer", pattern: "^{{customer_id}}-", data: map[string]any{"order_id": "C43-0001", "customer_id": "C42"}, want: false},
+		{name: "prefix not at start", pattern: "^{{customer_id}}-", data: map[string]any{"order_id": "XC42-0001", "customer_id": "C42"}, want: false},
+		{name: "embedded value is literal", pattern: "^{{customer_id}}-", data: map[string]any{"order_id": "C4X-0001", "customer_id": "C4."}, want: false},
+		{name: "nested and numeric reference", pattern: `^{{account.id}}/\d+$`, data: map[string]any{"order_id": "17/99", "account": map[string]any{"id": 17}}, want: true},
+		{name: "missing reference", pattern: "^{{customer_id}}-", data: map[string]any{"order_id": "C42-0001"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "order_id", Op: OperatorRegex, Value: tt.pattern}}}
+			res, err := Evaluate(rule, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegexIgnoresTemplateValues(t *testing.T) {
+	e := New()
+	e.TemplateValues = true
+	rule := Rule{Conditions: []Condition{{Field: "order_id", Op: OperatorRegex, Value: "^{{customer_id}}-"}}}
+	res, err := e.Evaluate(rule, map[string]any{"order_id": "C42-1", "customer_id": "C42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("regex field references should work with TemplateValues enabled")
+	}
+}
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		pattern any
+		want    bool
+		wantErr bool
+	}{
+		{name: "matching email", field: "ada@example.com", pattern: `^[^@\s]+@[^@\s]+\.[a-z]+$`, want: true},
+		{name: "non-matching email", field: "ada.example.com", pattern: `^[^@\s]+@[^@\s]+\.[a-z]+$`, want: false},
+		{name: "number coerced to string", field: 12345, pattern: `^\d{5}$`, want: true},
+		{name: "bool coerced to string", field: true, pattern: `^true$`, want: true},
+		{name: "invalid pattern", field: "x", pattern: `(`, wantErr: true},
+		{name: "non-string pattern", field: "x", pattern: 1, wantErr: true},
+		{name: "object field", field: map[string]any{}, pattern: `.`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorRegex, Value: tt.pattern}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegexCache(t *testing.T) {
+	e := New()
+	rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorRegex, Value: `^a+$`}}}
+	for _, v := range []string{"aaa", "b"} {
+		if _, err := e.Evaluate(rule, map[string]any{"v": v}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, ok := e.regexps.Load(`^a+$`)
+	if !ok {
+		t.Fatal("pattern not cached")
+	}
+	if _, err := e.Evaluate(rule, map[string]any{"v": "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := e.regexps.Load(`^a+$`); again != first {
+		t.Error("cached pattern was recompiled")
+	}
+	e.Evaluate(Rule{Conditions: []Condition{{Field: "v", Op: OperatorRegex, Value: `(`}}}, map[string]any{"v": "a"})
+	if _, ok := e.regexps.Load(`(`); ok {
+		t.Error("invalid pattern should not be cached")
+	}
+}
+
+func TestRegexCacheBounded(t *testing.T) {
+	e := New()
+	rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorRegex, ValueField: "pattern"}}}
+	for i := range maxCachedRegexps + 100 {
+		res, err := e.Evaluate(rule, map[string]any{"v": "id-" + strconv.Itoa(i), "pattern": "^id-" + strconv.Itoa(i) + "$"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Matched {
+			t.Fatalf("pattern %d did not match", i)
+		}
+	}
+	n := 0
+	e.regexps.Range(func(any, any) bool { n++; return true })
+	if n > maxCachedRegexps || e.regexpN.Load() != int64(n) {
+		t.Errorf("cache holds %d patterns, counted %d, want at most %d", n, e.regexpN.Load(), maxCachedRegexps)
+	}
+}
+
+func TestMatchesAtLeast(t *testing.T) {
+	patterns := []any{`(?i)\bfree\b`, `!{2,}`, `\$\d+`, `(?i)act now`}
+	tests := []struct {
+		name    string
+		subject any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "below k", subject: "Free shipping", value: map[string]any{"patterns": patterns, "k": 2}, want: false},
+		{name: "at k", subject: "FREE gift!!", value: map[string]any{"patterns": patterns, "k": 2}, want: true},
+		{name: "above k", subject: "Act now!! Free $100", value: map[string]any{"patterns": patterns, "k": 2}, want: true},
+		{name: "all required", subject: "free $5!!", value: map[string]any{"patterns": patterns, "k": 4}, want: false},
+		{name: "number subject", subject: 42, value: map[string]any{"patterns": []any{`^\d+$`, `2`}, "k": 2}, want: true},
+		{name: "k above pattern count", subject: "x", value: map[string]any{"patterns": patterns, "k": 5}, wantErr: true},
+		{name: "k zero", subject: "x", value: map[string]any{"patterns": patterns, "k": 0}, wantErr: true},
+		{name: "missing patterns", subject: "x", value: map[string]any{"k": 1}, wantErr: true},
+		{name: "invalid pattern", subject: "x", value: map[string]any{"patterns": []any{`(`}, "k": 1}, wantErr: true},
+		{name: "non-map value", subject: "x", value: "free", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "subject", Op: OperatorMatchesAtLeast, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"subject": tt.subject})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAtLeastCache(t *testing.T) {
+	e := New()
+	rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorMatchesAtLeast, Value: map[string]any{"patterns": []any{`^a`, `b$`}, "k": 1}}}}
+	if _, err := e.Evaluate(rule, map[string]any{"v": "xb"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{`^a`, `b$`} {
+		if _, ok := e.regexps.Load(p); !ok {
+			t.Errorf("pattern %q not cached", p)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package rules
+
+import "fmt"
+
+// FieldResolver supplies field values from a source other than the data map,
+// such as a database or remote service. Resolve reports whether path was
+// found; an error means the source could not answer, e.g. it timed out, and
+// is distinct from the field being absent.
+type FieldResolver interface {
+	Resolve(path string) (any, bool, error)
+}
+
+// FieldResolverFunc adapts a function to FieldResolver.
+type FieldResolverFunc func(path string) (any, bool, error)
+
+// Resolve calls f(path).
+func (f FieldResolverFunc) Resolve(path string) (any, bool, error) { return f(path) }
+
+// ResolveError reports a FieldResolver failing to resolve a field. It unwraps
+// to the resolver's error; it never wraps ErrFieldNotFound, so resolution
+// failures are not mistaken for missing fields, even under MissingAsNoMatch.
+type ResolveError struct {
+	Path string // field reference, a JSON Pointer when Engine.JSONPointer is set
+	Err  error
+}
+
+func (err *ResolveError) Error() string {
+	return fmt.Sprintf("resolving field %q: %v", err.Path, err.Err)
+}
+
+func (err *ResolveError) Unwrap() error { return err.Err }
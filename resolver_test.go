@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFieldResolver(t *testing.T) {
+	resolver := FieldResolverFunc(func(path string) (any, bool, error) {
+		switch path {
+		case "account.tier":
+			return "gold", true, nil
+		case "account.balance":
+			return nil, false, context.DeadlineExceeded
+		}
+		return nil, false, nil
+	})
+	tests := []struct {
+		name        string
+		cond        Condition
+		opts        Options
+		want        bool
+		wantMissing bool
+		wantResolve bool
+	}{
+		{name: "resolved field", cond: Condition{Field: "account.tier", Op: OperatorEQ, Value: "gold"}, want: true},
+		{name: "data takes precedence", cond: Condition{Field: "plan", Op: OperatorEQ, Value: "pro"}, want: true},
+		{name: "resolved value field", cond: Condition{Field: "plan_tier", Op: OperatorEQ, ValueField: "account.tier"}, want: true},
+		{name: "not found", cond: Condition{Field: "account.owner", Op: OperatorEQ, Value: "x"}, wantMissing: true},
+		{name: "resolver error", cond: Condition{Field: "account.balance", Op: OperatorGT, Value: 0}, wantResolve: true},
+		{name: "resolver error in value field", cond: Condition{Field: "plan", Op: OperatorEQ, ValueField: "account.balance"}, wantResolve: true},
+		{
+			name:        "resolver error is not a missing field",
+			cond:        Condition{Field: "account.balance", Op: OperatorGT, Value: 0},
+			opts:        Options{MissingFieldBehavior: MissingAsNoMatch},
+			wantResolve: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewWithOptions(tt.opts)
+			e.Resolver = resolver
+			res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, map[string]any{"plan": "pro", "plan_tier": "gold"})
+			if got := errors.Is(err, ErrFieldNotFound); got != tt.wantMissing {
+				t.Errorf("errors.Is(%v, ErrFieldNotFound) = %v, want %v", err, got, tt.wantMissing)
+			}
+			var rerr *ResolveError
+			if got := errors.As(err, &rerr); got != tt.wantResolve {
+				t.Fatalf("errors.As(%v, *ResolveError) = %v, want %v", err, got, tt.wantResolve)
+			}
+			if tt.wantResolve && (rerr.Path != "account.balance" || !errors.Is(err, context.DeadlineExceeded)) {
+				t.Errorf("ResolveError = %+v, want path account.balance wrapping DeadlineExceeded", rerr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
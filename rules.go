@@ -4,13 +4,35 @@
 package rules
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sentinel errors wrapped by evaluation errors, for use with errors.Is.
+var (
+	// ErrFieldNotFound reports a condition referencing a field missing from
+	// the data.
+	ErrFieldNotFound = errors.New("field not found")
+	// ErrUnknownOperator reports a condition using an operator that is not
+	// registered.
+	ErrUnknownOperator = errors.New("unknown operator")
+	// ErrNullOperand reports an operator that is not defined for null
+	// applied to a null field or value, such as gt or contains.
+	ErrNullOperand = errors.New("null operand")
+	// ErrMaxDuration reports an evaluation exceeding the engine's
+	// MaxDuration. It wraps context.DeadlineExceeded.
+	ErrMaxDuration = fmt.Errorf("evaluation exceeded MaxDuration: %w", context.DeadlineExceeded)
 )
 
 // Operator defines supported comparison operators.
@@ -25,13 +47,65 @@ const (
 	OperatorLTE      Operator = "lte"
 	OperatorContains Operator = "contains"
 	OperatorIn       Operator = "in"
+	OperatorNotIn    Operator = "notin"
+	// OperatorBetween matches a numeric or date field within an inclusive
+	// range given as [min, max].
+	OperatorBetween Operator = "between"
 )
 
-// Condition is a single field-operator-value check.
+// Condition is a single field-operator-value check. A string Value of the
+// form "$field:<path>" refers to another field, e.g. "$field:submitted_at",
+// and is resolved against the data before the operator runs.
+//
+// A field set to null (nil) is present, unlike a missing field: eq and in
+// match it against a nil Value or a list containing nil, ne and notin match
+// it against anything else, and exists matches it. Operators ordering or
+// inspecting values, such as gt, between, contains, startswith, regex and
+// len, fail with an error wrapping ErrNullOperand when either operand is
+// null.
 type Condition struct {
 	Field string   `json:"field"`
 	Op    Operator `json:"op"`
 	Value any      `json:"value"`
+
+	// OpField, used when Op is empty, names a field holding the operator,
+	// so the data can choose how the condition compares.
+	OpField string `json:"opField,omitempty"`
+
+	// FieldBy, when set, chooses Field from the data's discriminator value,
+	// for polymorphic data whose shape depends on a "type" field.
+	FieldBy *FieldSelector `json:"fieldBy,omitempty"`
+
+	// ValueField, when set, compares against another field instead of Value.
+	// A non-zero Percent scales that field's numeric value, so
+	// {"op":"gte","valueField":"limit","percent":80} means "at least 80% of limit".
+	ValueField string  `json:"valueField,omitempty"`
+	Percent    float64 `json:"percent,omitempty"`
+
+	// Pct is the tolerance band, in percent of the condition value, used by
+	// OperatorWithinPct.
+	Pct float64 `json:"pct,omitempty"`
+
+	// Unit, when set to UnitDuration or UnitBytes, normalises both operands
+	// to that unit before comparing.
+	Unit string `json:"unit,omitempty"`
+
+	// Transform names a transformer (see RegisterTransform) applied to the
+	// field value before the operator runs.
+	Transform string `json:"transform,omitempty"`
+
+	// Transforms names transformers applied left to right after Transform,
+	// e.g. ["trim", "lower", "slugify"], each receiving the previous output.
+	Transforms []string `json:"transforms,omitempty"`
+
+	// Negate flips the condition's outcome after the operator runs.
+	Negate bool `json:"negate,omitempty"`
+
+	// When, of the form "$cond:<index>", makes the condition an implication:
+	// it only has to match if the earlier condition at that index matched,
+	// and is vacuously true otherwise. The referenced condition then only
+	// serves as the antecedent and does not itself decide the rule.
+	When string `json:"when,omitempty"`
 }
 
 // Logic combines multiple conditions.
@@ -40,47 +114,398 @@ type Logic string
 const (
 	LogicAND Logic = "and"
 	LogicOR  Logic = "or"
+	// LogicNOT matches when the conditions, combined with AND, do not all
+	// match, e.g. "allow unless blocked".
+	LogicNOT Logic = "not"
 )
 
 // Rule is a declarative, JSON-friendly rule.
 type Rule struct {
 	Conditions []Condition `json:"conditions"`
 	Logic      Logic       `json:"logic,omitempty"` // defaults to AND
+
+	// Root, when set, is a nested group evaluated instead of Conditions and
+	// Logic.
+	Root *Group `json:"root,omitempty"`
 }
 
 // Result is the machine-readable evaluation outcome.
 type Result struct {
 	Matched     bool   `json:"matched"`
 	Explanation string `json:"explanation,omitempty"`
+
+	// Variants records the variant assigned by each OperatorVariant
+	// condition evaluated, keyed by salt.
+	Variants map[string]string `json:"variants,omitempty"`
+
+	// Tree is the explanation tree of a rule with a Root group; Explanation
+	// then holds its one-line rendering.
+	Tree *ExplanationNode `json:"tree,omitempty"`
+
+	// Trace records each condition evaluated, in order, when the engine's
+	// Trace is set.
+	Trace []ConditionResult `json:"trace,omitempty"`
+
+	// Fallback reports that EvaluateWithFallback produced the result from
+	// its fallback rule, because evaluating the primary rule failed with
+	// PrimaryError.
+	Fallback     bool   `json:"fallback,omitempty"`
+	PrimaryError string `json:"primaryError,omitempty"`
 }
 
-// Engine holds registered operators (minimal state, reusable).
+// Engine holds registered operators (minimal state, reusable). Its Register
+// methods are safe to call concurrently with each other and with
+// evaluations; its exported fields must be set before the engine is shared.
 type Engine struct {
-	ops map[Operator]func(any, any) (bool, error)
+	mu     sync.RWMutex // guards the registry maps that follow
+	ops    map[Operator]func(any, any) (bool, error)
+	ctxOps map[Operator]func(context.Context, any, any, map[string]any) (bool, error)
+	// fieldOps are built-ins that resolve their own operands from the
+	// condition, e.g. to read the same field from two namespaces.
+	fieldOps    map[Operator]func(context.Context, *evalState, Condition, map[string]any) (bool, error)
+	enums       map[string]map[string]struct{}
+	schemas     map[string]*jsonSchema
+	transforms  map[string]func(any) (any, error)
+	transitions map[string]map[string][]string
+	fuzzy       map[Operator]func(any, any) (float64, error)
+	virtuals    map[string]func(map[string]any) (any, error)
+	comparers   map[reflect.Type]func(any, any) (int, error)
+	regexps     sync.Map     // pattern → *regexp.Regexp
+	regexpN     atomic.Int64 // entries in regexps, see maxCachedRegexps
+	opts        Options
+
+	// Tolerance sets, per built-in comparison operator (eq, ne, gt, gte, lt,
+	// lte), how far apart two numbers may be and still count as equal. For
+	// example a gte tolerance of 0.5 lets 9.6 satisfy "gte 10", while a gt
+	// tolerance requires exceeding the value by more than the tolerance.
+	// Operators without an entry use zero, i.e. exact comparison.
+	Tolerance map[Operator]float64
+
+	// Holidays and Location configure OperatorBusinessDay. Only the calendar
+	// date of each holiday is used; dates being checked are converted to
+	// Location first, which defaults to UTC. Location is also the time zone
+	// of OperatorCronNextWithin schedules.
+	Holidays []time.Time
+	Location *time.Location
+
+	// Now is the engine's clock, used for "$now" values, the age operators
+	// and DecisionLog timestamps; nil means time.Now.
+	Now func() time.Time
+
+	// Resolver, when set, supplies fields missing from the data, e.g. from a
+	// database. Its errors abort the evaluation as a *ResolveError.
+	Resolver FieldResolver
+
+	// FallbackPrefix, when set, names a namespace consulted for fields missing
+	// from the data: with "defaults", a missing "region" resolves from
+	// "defaults.region". This supports layered configuration.
+	FallbackPrefix string
+
+	// ResultHook, when set, is called with every successful top-level result
+	// before it is returned, letting callers enrich results (for example with
+	// a recommended action) without wrapping each call site.
+	ResultHook func(rule Rule, data map[string]any, res Result) Result
+
+	// TemplateValues enables string condition values containing "{{" to be
+	// rendered as text/template templates against the data before
+	// comparison, e.g. "{{ .base }}". Templates cannot use "call", and
+	// referencing a missing key is an error. Regex patterns are never
+	// rendered, as they embed fields with their own {{path}} syntax. Off by
+	// default.
+	TemplateValues bool
+
+	// Counters resolves {"$counter": key} condition values, keeping counter
+	// state such as request rates outside the engine.
+	Counters CounterProvider
+
+	// Sets resolves "$set:" condition values to named sets, typically used
+	// with the in operator. Wrap slow sources in a CachedSetProvider.
+	Sets SetProvider
+
+	// Windows resolves "$window:" references to windowed aggregates supplied
+	// by the caller, keeping windowing outside the engine.
+	Windows func(ctx context.Context, spec WindowSpec) (float64, error)
+
+	// Tracer, when set, records a span for every top-level evaluation with
+	// the rule hash, outcome and duration as attributes. The
+	// github.com/njchilds90/go-rules/otel module adapts OpenTelemetry.
+	Tracer Tracer
+
+	// JSONPointer makes explanations and field-not-found errors reference
+	// fields as RFC 6901 JSON Pointers ("/user/age") instead of dot paths
+	// ("user.age"), for tools that edit the underlying JSON.
+	JSONPointer bool
+
+	// RecoverPanics converts a panic in an operator or transformer into an
+	// error from the evaluation, carrying the panic value and stack trace,
+	// so one faulty custom operator cannot crash the caller. Off by default
+	// so that bugs are not masked.
+	RecoverPanics bool
+
+	// Memoize caches contextual operator results within a single evaluation,
+	// keyed by operator and operands, so duplicate conditions call the
+	// operator once. Simple operators are never memoized.
+	Memoize bool
+
+	// Trace makes results record each condition evaluated, with its
+	// resolved operands, in Result.Trace.
+	Trace bool
+
+	// ExplainAll disables short-circuiting so that every condition is
+	// evaluated, for debugging: explanations then list each condition's
+	// outcome, and Result.Trace every condition, whatever the logic. The
+	// overall outcome is unchanged, but operators run that otherwise would
+	// not, and an error from one of them fails the evaluation.
+	ExplainAll bool
+
+	// MaxDuration, when positive, caps the wall-clock time of each top-level
+	// evaluation, including each record of EvaluateBatch, regardless of the
+	// caller's context. Evaluations over budget fail with ErrMaxDuration.
+	// The budget is checked between conditions and passed to contextual
+	// operators in their context; an operator ignoring it is not interrupted,
+	// but its result is discarded once the budget is spent.
+	MaxDuration time.Duration
+}
+
+// evalState carries per-evaluation bookkeeping through evalCondition.
+type evalState struct {
+	memo     map[string]bool
+	variants map[string]string
+	tracing  bool
+	trace    []ConditionResult
+	ops      map[Operator]operator // resolved by Compile; nil otherwise
+	inputs   map[string]any        // field values read, for EvaluateWithLog
+	nested   int                   // depth of quantifier element conditions
+	operands *ConditionResult      // see resolved
+}
+
+// record appends r to the trace when tracing.
+func (st *evalState) record(r ConditionResult) {
+	if st.tracing {
+		st.trace = append(st.trace, r)
+	}
+}
+
+// resolved hands the operands an operator resolved for itself to the match
+// awaiting them, once, so that the element conditions of quantifiers cannot
+// overwrite them.
+func (st *evalState) resolved(v, want any) {
+	if st.operands != nil {
+		st.operands.Actual, st.operands.Expected = v, want
+		st.operands = nil
+	}
+}
+
+// input records v as the value of the field at path when recording inputs,
+// except for the element conditions of quantifiers, whose paths are relative
+// to each element.
+func (st *evalState) input(path string, v any) {
+	if st.inputs != nil && st.nested == 0 {
+		st.inputs[path] = v
+	}
 }
 
 // New creates a new Engine with built-in operators.
 func New() *Engine {
-	e := &Engine{ops: make(map[Operator]func(any, any) (bool, error))}
+	e := &Engine{
+		ops:         make(map[Operator]func(any, any) (bool, error)),
+		ctxOps:      make(map[Operator]func(context.Context, any, any, map[string]any) (bool, error)),
+		fieldOps:    make(map[Operator]func(context.Context, *evalState, Condition, map[string]any) (bool, error)),
+		enums:       make(map[string]map[string]struct{}),
+		schemas:     make(map[string]*jsonSchema),
+		transforms:  make(map[string]func(any) (any, error)),
+		transitions: make(map[string]map[string][]string),
+		fuzzy:       make(map[Operator]func(any, any) (float64, error)),
+		virtuals:    make(map[string]func(map[string]any) (any, error)),
+		comparers:   make(map[reflect.Type]func(any, any) (int, error)),
+	}
 	e.registerDefaults()
+	e.registerDefaultTransforms()
 	return e
 }
 
 func (e *Engine) registerDefaults() {
-	e.ops[OperatorEQ] = func(a, b any) (bool, error) { return equal(a, b), nil }
-	e.ops[OperatorNE] = func(a, b any) (bool, error) { return !equal(a, b), nil }
-	e.ops[OperatorGT] = greater
-	e.ops[OperatorGTE] = func(a, b any) (bool, error) { return greaterOrEqual(a, b) }
-	e.ops[OperatorLT] = func(a, b any) (bool, error) { return less(a, b) }
-	e.ops[OperatorLTE] = func(a, b any) (bool, error) { return lessOrEqual(a, b) }
-	e.ops[OperatorContains] = contains
-	e.ops[OperatorIn] = in
+	e.ops[OperatorEQ] = e.caseless(e.comparing(func(c int) bool { return c == 0 }, func(a, b any) (bool, error) {
+		return equalWithin(a, b, e.Tolerance[OperatorEQ], e.opts.StrictTypes), nil
+	}))
+	e.ops[OperatorNE] = e.caseless(e.comparing(func(c int) bool { return c != 0 }, func(a, b any) (bool, error) {
+		return !equalWithin(a, b, e.Tolerance[OperatorNE], e.opts.StrictTypes), nil
+	}))
+	e.ops[OperatorGT] = e.comparing(func(c int) bool { return c > 0 }, func(a, b any) (bool, error) { return greater(a, b, e.Tolerance[OperatorGT]) })
+	e.ops[OperatorGTE] = e.comparing(func(c int) bool { return c >= 0 }, func(a, b any) (bool, error) { return greaterOrEqual(a, b, e.Tolerance[OperatorGTE]) })
+	e.ops[OperatorLT] = e.comparing(func(c int) bool { return c < 0 }, func(a, b any) (bool, error) { return less(a, b, e.Tolerance[OperatorLT]) })
+	e.ops[OperatorLTE] = e.comparing(func(c int) bool { return c <= 0 }, func(a, b any) (bool, error) { return lessOrEqual(a, b, e.Tolerance[OperatorLTE]) })
+	e.ops[OperatorContains] = e.caseless(contains)
+	e.ops[OperatorStartsWith] = e.caseless(startsWith)
+	e.ops[OperatorEndsWith] = e.caseless(endsWith)
+	e.ops[OperatorIn] = e.caseless(e.in)
+	e.ops[OperatorNotIn] = e.caseless(e.notIn)
+	e.ops[OperatorBetween] = between
+	e.ctxOps[OperatorIntersectAtLeast] = e.intersectAtLeast
+	e.ctxOps[OperatorPermutationOf] = e.permutationOf
+	e.ops[OperatorSortedBy] = e.sortedBy
+	e.ops[OperatorNonDecreasing] = monotonic(OperatorNonDecreasing, func(c int) bool { return c <= 0 })
+	e.ops[OperatorNonIncreasing] = monotonic(OperatorNonIncreasing, func(c int) bool { return c >= 0 })
+	e.ops[OperatorIncreasing] = monotonic(OperatorIncreasing, func(c int) bool { return c < 0 })
+	e.ops[OperatorDecreasing] = monotonic(OperatorDecreasing, func(c int) bool { return c > 0 })
+	e.ops[OperatorInControl] = inControl
+	e.ops[OperatorKeysEqual] = keysEqual
+	e.ops[OperatorGlobList] = globList
+	e.ops[OperatorInEnum] = e.inEnum
+	e.ops[OperatorMatchesSchema] = e.matchesSchema
+	e.ops[OperatorLuhn] = luhn
+	e.ops[OperatorIsValidIBAN] = isValidIBAN
+	e.ops[OperatorIsValidISBN] = isValidISBN
+	e.ops[OperatorBusinessDay] = e.businessDay
+	e.ops[OperatorAgeGT] = e.ageGT
+	e.ops[OperatorAgeLT] = e.ageLT
+	e.ops[OperatorCronNextWithin] = e.cronNextWithin
+	e.ops[OperatorEntropy] = entropyGTE
+	e.ops[OperatorCase] = isCase
+	e.ops[OperatorSimilar] = similar
+	e.ops[OperatorMultipleOf] = multipleOf
+	e.ops[OperatorIsTimezone] = isTimezone
+	e.ops[OperatorIsLocale] = isLocale
+	e.ops[OperatorIsPort] = isPort
+	e.ops[OperatorMatchesAtLeast] = e.matchesAtLeast
+	e.fuzzy[OperatorSimilar] = similarity
+	e.ctxOps[OperatorJSONMatch] = e.jsonMatch
+	e.ctxOps[OperatorSameFormat] = e.sameFormat
+	e.ctxOps[OperatorRegex] = e.regex
+	e.fieldOps[OperatorChangedByAtLeast] = e.changedByAtLeast
+	e.fieldOps[OperatorAdded] = e.added
+	e.fieldOps[OperatorExists] = e.exists
+	e.fieldOps[OperatorNotExists] = e.notExists
+	e.fieldOps[OperatorRemoved] = e.removed
+	e.fieldOps[OperatorTransition] = e.transition
+	e.fieldOps[OperatorWithinPct] = e.withinPct
+	e.fieldOps[OperatorCurve] = e.curve
+	e.fieldOps[OperatorVariant] = e.variant
+	e.fieldOps[OperatorLen] = e.length
+	e.fieldOps[OperatorWithinDuration] = e.withinDuration
+	e.fieldOps[OperatorStable] = e.stable
+	e.fieldOps[OperatorAll] = e.quantify
+	e.fieldOps[OperatorAny] = e.quantify
 }
 
+// Register registers a simple operator, called with a condition's resolved
+// field value and condition value. Use RegisterContextual for operators that
+// need more of the data.
 func (e *Engine) Register(op Operator, fn func(any, any) (bool, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	e.ops[op] = fn
 }
 
+// RegisterContextual registers an operator that also receives the evaluation
+// context and the full data map, for operators that depend on more than the
+// two operands, such as "within 10% of the average in another field". A
+// contextual operator takes precedence over a simple operator registered
+// under the same name.
+//
+// A condition is evaluated in this order:
+//
+//  1. FieldBy and OpField choose the field and operator from the data.
+//  2. The field value is looked up (see Engine.Resolver, RegisterVirtual
+//     and Engine.FallbackPrefix) and its transforms applied.
+//  3. The condition value is resolved: ValueField, "$field:" and other
+//     references, templates, Percent and then Unit normalisation.
+//  4. The operator runs: a built-in that resolves its own operands, else a
+//     contextual operator (memoized when Engine.Memoize is set), else a
+//     simple one.
+//  5. Negate is applied.
+//
+// fieldVal and condVal are the operands from steps 2 and 3, and data is the
+// data being evaluated, unmodified; operators must not modify it.
+func (e *Engine) RegisterContextual(op Operator, fn func(ctx context.Context, fieldVal, condVal any, data map[string]any) (bool, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ctxOps[op] = fn
+}
+
+// RegisterVirtual registers a computed field, resolved by calling fn with
+// the data whenever a condition references path name and the data itself
+// has no such field. For example a "full_name" virtual field can join
+// "first" and "last". Errors from fn fail the evaluation.
+func (e *Engine) RegisterVirtual(name string, fn func(data map[string]any) (any, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.virtuals[name] = fn
+}
+
+// Options configures an Engine created with NewWithOptions.
+type Options struct {
+	// CaseInsensitive makes eq, ne, contains, in, notin, startswith and endswith
+	// compare strings ignoring case. Other operators and non-string values
+	// are unaffected.
+	CaseInsensitive bool
+
+	// MissingFieldBehavior says how a condition referencing a field missing
+	// from the data is handled. The default, MissingError, fails the
+	// evaluation with ErrFieldNotFound.
+	MissingFieldBehavior MissingFieldBehavior
+
+	// StrictTypes stops eq, ne, in and notin from treating numeric strings
+	// as numbers, so "1" does not equal 1. Numbers of different Go types,
+	// such as 1 and 1.0, still compare by value. Ordering operators are
+	// unaffected.
+	StrictTypes bool
+}
+
+// MissingFieldBehavior selects how conditions referencing missing fields are
+// handled.
+type MissingFieldBehavior int
+
+const (
+	// MissingError fails the evaluation with an error wrapping
+	// ErrFieldNotFound.
+	MissingError MissingFieldBehavior = iota
+	// MissingAsNoMatch makes the condition not match, whether or not it is
+	// negated, with an explanation naming the missing field. This suits
+	// feature flags, where an absent attribute means the condition does not
+	// apply.
+	MissingAsNoMatch
+)
+
+// NewWithOptions creates a new Engine with built-in operators configured by
+// opts.
+func NewWithOptions(opts Options) *Engine {
+	e := New()
+	e.opts = opts
+	return e
+}
+
+// caseless wraps a built-in string operator to fold the case of string
+// operands, and of strings within slice operands, when the engine is
+// case-insensitive.
+func (e *Engine) caseless(fn func(any, any) (bool, error)) func(any, any) (bool, error) {
+	return func(a, b any) (bool, error) {
+		if !e.opts.CaseInsensitive {
+			return fn(a, b)
+		}
+		return fn(foldCase(a), foldCase(b))
+	}
+}
+
+// foldCase lower-cases strings, leaving dates alone so that their "T" and
+// "Z" still parse.
+func foldCase(v any) any {
+	switch x := v.(type) {
+	case string:
+		if _, ok := toTime(x); ok {
+			return x
+		}
+		return strings.ToLower(x)
+	case []any:
+		out := make([]any, len(x))
+		for i, item := range x {
+			out[i] = foldCase(item)
+		}
+		return out
+	}
+	return v
+}
+
 // Default is the shared default engine.
 var Default = New()
 
@@ -99,62 +524,490 @@ func (e *Engine) Evaluate(rule Rule, data map[string]any) (Result, error) {
 }
 
 func (e *Engine) EvaluateWithContext(ctx context.Context, rule Rule, data map[string]any) (Result, error) {
+	p, err := newPlan(rule)
+	if err != nil {
+		return Result{}, err
+	}
+	return e.evaluateTopLevel(ctx, p, data)
+}
+
+// evaluateTopLevel evaluates p for a caller, as opposed to a sub-rule
+// operator: within the MaxDuration, traced and with the ResultHook applied.
+func (e *Engine) evaluateTopLevel(ctx context.Context, p *plan, data map[string]any) (Result, error) {
+	return e.withinBudget(ctx, func(ctx context.Context) (Result, error) {
+		if e.Tracer != nil {
+			return e.evaluateTraced(ctx, p, data)
+		}
+		return e.evaluateHooked(ctx, p, data)
+	})
+}
+
+// withinBudget runs eval with the engine's MaxDuration applied to ctx,
+// failing with ErrMaxDuration once it is spent.
+func (e *Engine) withinBudget(ctx context.Context, eval func(context.Context) (Result, error)) (Result, error) {
+	if e.MaxDuration <= 0 {
+		return eval(ctx)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, e.MaxDuration, ErrMaxDuration)
+	defer cancel()
+	res, err := eval(ctx)
+	if context.Cause(ctx) == ErrMaxDuration {
+		return Result{}, ErrMaxDuration
+	}
+	return res, err
+}
+
+// evaluateHooked evaluates p and applies the ResultHook.
+func (e *Engine) evaluateHooked(ctx context.Context, p *plan, data map[string]any) (Result, error) {
+	res, err := e.evaluatePlan(ctx, p, data)
+	if err != nil {
+		return Result{}, err
+	}
+	if e.ResultHook != nil {
+		res = e.ResultHook(p.rule, data, res)
+	}
+	return res, nil
+}
+
+// evaluate evaluates rule without applying the ResultHook, as used for the
+// nested rules of sub-rule operators.
+func (e *Engine) evaluate(ctx context.Context, rule Rule, data map[string]any) (Result, error) {
+	p, err := newPlan(rule)
+	if err != nil {
+		return Result{}, err
+	}
+	return e.evaluatePlan(ctx, p, data)
+}
+
+// plan is the part of evaluating a rule that does not depend on the data,
+// derived once so that EvaluateBatch and CompiledRule can reuse it.
+type plan struct {
+	rule        Rule
+	logic       Logic                 // rule.Logic, defaulted to AND
+	antecedents []bool                // see antecedents
+	ops         map[Operator]operator // set by Compile
+	inputs      map[string]any        // set by EvaluateWithLog
+}
+
+func newPlan(rule Rule) (*plan, error) {
+	p := &plan{rule: rule, logic: rule.Logic}
+	if p.logic == "" {
+		p.logic = LogicAND
+	}
+	if rule.Root == nil {
+		refs, err := antecedents(rule.Conditions)
+		if err != nil {
+			return nil, err
+		}
+		p.antecedents = refs
+	}
+	return p, nil
+}
+
+// evaluatePlan evaluates p's rule against data.
+func (e *Engine) evaluatePlan(ctx context.Context, p *plan, data map[string]any) (Result, error) {
 	if ctx.Err() != nil {
 		return Result{}, ctx.Err()
 	}
-	if len(rule.Conditions) == 0 {
+	st := &evalState{tracing: e.Trace, ops: p.ops, inputs: p.inputs}
+	if p.rule.Root != nil {
+		tree, err := e.evalGroup(ctx, st, p.rule.Root, data)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Matched: tree.Matched, Explanation: tree.String(), Variants: st.variants, Tree: tree, Trace: st.trace}, nil
+	}
+	if len(p.rule.Conditions) == 0 {
 		return Result{Matched: true}, nil
 	}
-	logic := rule.Logic
-	if logic == "" {
-		logic = LogicAND
+	combine := p.logic
+	if p.logic == LogicNOT {
+		combine = LogicAND
 	}
-	if logic == LogicAND {
-		for _, c := range rule.Conditions {
-			matched, expl, err := e.evalCondition(ctx, c, data)
-			if err != nil {
-				return Result{}, err
-			}
-			if !matched {
-				return Result{Matched: false, Explanation: expl}, nil
-			}
-		}
-		return Result{Matched: true, Explanation: "all conditions met"}, nil
+	res, err := e.evalConditions(ctx, st, p.rule.Conditions, p.antecedents, combine, data)
+	if err != nil {
+		return Result{}, err
+	}
+	if p.logic == LogicNOT {
+		res = Result{Matched: !res.Matched, Explanation: "NOT (" + res.Explanation + ")"}
 	}
-	// OR
-	for _, c := range rule.Conditions {
-		matched, expl, err := e.evalCondition(ctx, c, data)
+	res.Variants = st.variants
+	res.Trace = st.trace
+	return res, nil
+}
+
+// evalConditions combines conditions with logic, left to right and
+// short-circuiting. Conditions referenced by another condition's When are
+// antecedents: their results are recorded but do not decide the rule.
+func (e *Engine) evalConditions(ctx context.Context, st *evalState, conds []Condition, antecedents []bool, logic Logic, data map[string]any) (Result, error) {
+	results := make([]bool, len(conds))
+	decided := false
+	var expls []string
+	for i, c := range conds {
+		matched, expl, err := e.evalImplication(ctx, st, c, results, data)
 		if err != nil {
 			return Result{}, err
 		}
-		if matched {
-			return Result{Matched: true, Explanation: expl}, nil
+		results[i] = matched
+		if e.ExplainAll {
+			expls = append(expls, expl)
+		}
+		if antecedents[i] {
+			continue
+		}
+		if matched == (logic == LogicOR) {
+			if !e.ExplainAll {
+				return Result{Matched: matched, Explanation: expl}, nil
+			}
+			decided = true
+		}
+	}
+	if e.ExplainAll {
+		sep := " AND "
+		if logic == LogicOR {
+			sep = " OR "
 		}
+		return Result{Matched: decided == (logic == LogicOR), Explanation: strings.Join(expls, sep)}, nil
+	}
+	if logic == LogicAND {
+		return Result{Matched: true, Explanation: "all conditions met"}, nil
 	}
 	return Result{Matched: false, Explanation: "no conditions met"}, nil
 }
 
-func (e *Engine) evalCondition(ctx context.Context, c Condition, data map[string]any) (bool, string, error) {
+func (e *Engine) evalCondition(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, string, error) {
 	if ctx.Err() != nil {
 		return false, "", ctx.Err()
 	}
-	v, ok := getValue(data, c.Field)
+	c, err := e.selectCondition(c, data)
+	if err != nil {
+		return false, "", err
+	}
+	matched, actual, expected, err := e.match(ctx, st, c, data)
+	var missing *missingFieldError
+	if err != nil && e.opts.MissingFieldBehavior == MissingAsNoMatch && errors.As(err, &missing) {
+		st.record(ConditionResult{Field: e.fieldRef(c.Field), Op: c.Op, Expected: c.Value, Missing: true})
+		return false, fmt.Sprintf("%s %s %v: field %q missing → false", e.fieldRef(c.Field), c.Op, e.describeValue(c), missing.ref), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	expl := fmt.Sprintf("%s %s %v", e.fieldRef(c.Field), c.Op, e.describeValue(c))
+	if c.Negate {
+		matched = !matched
+		expl = "NOT " + expl
+	}
+	st.record(ConditionResult{Field: e.fieldRef(c.Field), Op: c.Op, Expected: expected, Actual: actual, Negated: c.Negate, Matched: matched})
+	return matched, fmt.Sprintf("%s → %t", expl, matched), nil
+}
+
+// selectCondition applies c's FieldBy and OpField, choosing its field and
+// operator from the data.
+func (e *Engine) selectCondition(c Condition, data map[string]any) (Condition, error) {
+	if c.FieldBy != nil {
+		path, err := e.resolveFieldBy(c.FieldBy, data)
+		if err != nil {
+			return Condition{}, err
+		}
+		c.Field, c.FieldBy = path, nil
+	}
+	if c.Op == "" && c.OpField != "" {
+		op, err := e.resolveOp(c.OpField, data)
+		if err != nil {
+			return Condition{}, err
+		}
+		c.Op = op
+	}
+	return c, nil
+}
+
+// resolveOp reads an operator name from the field at path, checking that
+// the operator is registered.
+func (e *Engine) resolveOp(path string, data map[string]any) (Operator, error) {
+	v, err := e.field(data, path)
+	if err != nil {
+		return "", err
+	}
+	name, ok := v.(string)
 	if !ok {
-		return false, "", fmt.Errorf("field %q not found: %w", c.Field, errors.New("field not found"))
+		return "", fmt.Errorf("operator field %q must be a string, got %T", path, v)
+	}
+	op := Operator(name)
+	if !e.hasOperator(op) {
+		return "", fmt.Errorf("%w %q from field %q", ErrUnknownOperator, name, path)
 	}
-	fn, ok := e.ops[c.Op]
+	return op, nil
+}
+
+// hasOperator reports whether op is registered.
+func (e *Engine) hasOperator(op Operator) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if _, ok := e.fieldOps[op]; ok {
+		return true
+	}
+	if _, ok := e.ctxOps[op]; ok {
+		return true
+	}
+	_, ok := e.ops[op]
+	return ok
+}
+
+// match resolves a condition's operands and runs its operator, returning the
+// operands for the trace. Operators that resolve their own operands report
+// them only when tracing, and report the condition value alone when they
+// resolve the field in some other way.
+func (e *Engine) match(ctx context.Context, st *evalState, c Condition, data map[string]any) (matched bool, v, want any, err error) {
+	if e.RecoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				matched, err = false, fmt.Errorf("operator %q panicked: %v\n%s", c.Op, r, debug.Stack())
+			}
+		}()
+	}
+	if fieldOp := e.operator(st, c.Op).field; fieldOp != nil {
+		if !st.tracing {
+			matched, err = fieldOp(ctx, st, c, data)
+			return matched, nil, nil, err
+		}
+		got := &ConditionResult{Expected: c.Value}
+		defer func(outer *ConditionResult) { st.operands = outer }(st.operands)
+		st.operands = got
+		matched, err = fieldOp(ctx, st, c, data)
+		return matched, got.Actual, got.Expected, err
+	}
+	if v, want, err = e.operands(ctx, st, c, data); err != nil {
+		return false, nil, nil, err
+	}
+	matched, err = e.apply(ctx, st, c.Op, v, want, data)
+	return matched, v, want, err
+}
+
+// operands resolves the field value and comparison value of a condition,
+// applying any transform and unit normalisation.
+func (e *Engine) operands(ctx context.Context, st *evalState, c Condition, data map[string]any) (v, want any, err error) {
+	if strings.HasPrefix(c.Field, WindowRefPrefix) {
+		if v, err = e.window(ctx, c.Field); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if v, err = e.field(data, c.Field); err != nil {
+			return nil, nil, err
+		}
+		st.input(c.Field, v)
+	}
+	if c.Transform != "" {
+		if v, err = e.transform(c.Transform, v); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, name := range c.Transforms {
+		if v, err = e.transform(name, v); err != nil {
+			return nil, nil, err
+		}
+	}
+	if want, err = e.conditionValue(ctx, st, c, data); err != nil {
+		return nil, nil, err
+	}
+	if c.Unit != "" {
+		if v, err = normalizeUnit(c.Unit, v); err != nil {
+			return nil, nil, err
+		}
+		if want, err = normalizeUnit(c.Unit, want); err != nil {
+			return nil, nil, err
+		}
+	}
+	st.resolved(v, want)
+	return v, want, nil
+}
+
+// conditionValue returns the operand a condition compares against: the
+// ValueField's value, scaled by Percent when set, or else Value rendered as a
+// template (when TemplateValues is set) or with any reference resolved.
+func (e *Engine) conditionValue(ctx context.Context, st *evalState, c Condition, data map[string]any) (any, error) {
+	if c.ValueField == "" {
+		if s, ok := c.Value.(string); ok && e.TemplateValues && c.Op != OperatorRegex && strings.Contains(s, "{{") {
+			return executeTemplate(s, data)
+		}
+		return e.resolveValue(ctx, c.Value, data)
+	}
+	v, err := e.field(data, c.ValueField)
+	if err != nil {
+		return nil, err
+	}
+	st.input(c.ValueField, v)
+	if c.Percent == 0 {
+		return v, nil
+	}
+	f, ok := toFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("percent requires a numeric value in field %q", c.ValueField)
+	}
+	return f * c.Percent / 100, nil
+}
+
+// describeValue renders a condition's comparison operand for explanations.
+func (e *Engine) describeValue(c Condition) any {
+	switch {
+	case c.ValueField == "":
+		return c.Value
+	case c.Percent != 0:
+		return fmt.Sprintf("%g%% of %s", c.Percent, e.fieldRef(c.ValueField))
+	default:
+		return fieldRefPrefix + e.fieldRef(c.ValueField)
+	}
+}
+
+// fieldRefPrefix marks a condition value that refers to another field.
+const fieldRefPrefix = "$field:"
+
+// resolveValue resolves references in a condition value: "$field:" strings
+// become the referenced field's value, "$window:" strings the windowed
+// aggregate, "$set:" strings the named set, "$now" the engine's current time
+// and {"$counter": key} objects the counter's current value.
+func (e *Engine) resolveValue(ctx context.Context, v any, data map[string]any) (any, error) {
+	switch x := v.(type) {
+	case map[string]any:
+		if key, ok := x[CounterRefKey].(string); ok && len(x) == 1 {
+			return e.count(ctx, key)
+		}
+	case string:
+		if strings.HasPrefix(x, WindowRefPrefix) {
+			return e.window(ctx, x)
+		}
+		if strings.HasPrefix(x, SetRefPrefix) {
+			return e.set(ctx, x)
+		}
+		if strings.HasPrefix(x, QuantileRefPrefix) {
+			return nil, fmt.Errorf("%q is only supported by Filter", x)
+		}
+		if x == NowRef {
+			return e.now(), nil
+		}
+		if strings.HasPrefix(x, fieldRefPrefix) {
+			return e.field(data, strings.TrimPrefix(x, fieldRefPrefix))
+		}
+	}
+	return v, nil
+}
+
+// applySpec runs a nested comparison given as {"op":"gte","value":8} against
+// v. Operators that compute a quantity, such as a count, take their
+// condition value in this form to say how the quantity is compared.
+func (e *Engine) applySpec(ctx context.Context, st *evalState, name Operator, spec, v any, data map[string]any) (bool, error) {
+	m, _ := spec.(map[string]any)
+	op, ok := m["op"].(string)
 	if !ok {
-		return false, "", fmt.Errorf("unknown operator %q", c.Op)
+		return false, fmt.Errorf(`%s requires {"op":...,"value":...} value`, name)
+	}
+	return e.apply(ctx, st, Operator(op), v, m["value"], data)
+}
+
+// operator is the implementation registered for an operator; at most one
+// kind is set for a built-in, and none for an unknown operator.
+type operator struct {
+	field  func(context.Context, *evalState, Condition, map[string]any) (bool, error)
+	ctx    func(context.Context, any, any, map[string]any) (bool, error)
+	simple func(any, any) (bool, error)
+}
+
+// operator returns the implementation of op, taken from the compiled rule
+// being evaluated when it resolved op.
+func (e *Engine) operator(st *evalState, op Operator) operator {
+	if o, ok := st.ops[op]; ok {
+		return o
+	}
+	return e.lookupOperator(op)
+}
+
+// lookupOperator returns the implementation of op registered now.
+func (e *Engine) lookupOperator(op Operator) operator {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return operator{field: e.fieldOps[op], ctx: e.ctxOps[op], simple: e.ops[op]}
+}
+
+// apply runs the operator registered for op, preferring a contextual
+// operator over a simple one.
+func (e *Engine) apply(ctx context.Context, st *evalState, op Operator, a, b any, data map[string]any) (bool, error) {
+	o := e.operator(st, op)
+	if o.ctx != nil {
+		if !e.Memoize {
+			return o.ctx(ctx, a, b, data)
+		}
+		key := fmt.Sprintf("%s\x00%#v\x00%#v", op, a, b)
+		if matched, ok := st.memo[key]; ok {
+			return matched, nil
+		}
+		matched, err := o.ctx(ctx, a, b, data)
+		if err != nil {
+			return false, err
+		}
+		if st.memo == nil {
+			st.memo = make(map[string]bool)
+		}
+		st.memo[key] = matched
+		return matched, nil
 	}
-	matched, err := fn(v, c.Value)
+	if o.simple == nil {
+		return false, fmt.Errorf("%w %q", ErrUnknownOperator, op)
+	}
+	return o.simple(a, b)
+}
+
+// lookup resolves path in data, falling back to the Resolver, a virtual
+// field registered under path and then to FallbackPrefix when the path
+// itself is absent.
+func (e *Engine) lookup(data map[string]any, path string) (any, bool, error) {
+	if v, ok := getValue(data, path); ok {
+		return v, true, nil
+	}
+	if e.Resolver != nil {
+		v, ok, err := e.Resolver.Resolve(path)
+		if err != nil {
+			return nil, false, &ResolveError{Path: e.fieldRef(path), Err: err}
+		}
+		if ok {
+			return v, true, nil
+		}
+	}
+	e.mu.RLock()
+	fn, ok := e.virtuals[path]
+	e.mu.RUnlock()
+	if ok {
+		v, err := fn(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("virtual field %q: %w", path, err)
+		}
+		return v, true, nil
+	}
+	if e.FallbackPrefix == "" {
+		return nil, false, nil
+	}
+	v, ok := getValue(data, e.FallbackPrefix+"."+path)
+	return v, ok, nil
+}
+
+// field is lookup for fields that must be present.
+func (e *Engine) field(data map[string]any, path string) (any, error) {
+	v, ok, err := e.lookup(data, path)
 	if err != nil {
-		return false, "", err
+		return nil, err
+	}
+	if !ok {
+		return nil, e.fieldNotFound(path)
 	}
-	expl := fmt.Sprintf("%s %s %v → %t", c.Field, c.Op, c.Value, matched)
-	return matched, expl, nil
+	return v, nil
 }
 
-// Helper: getValue supports dot notation for nested maps.
+// Helper: getValue supports dot notation for nested maps. Besides
+// map[string]any, any map whose key kind is a string (including named string
+// types), a signed or unsigned integer, or a bool is traversed via
+// reflection, with each path segment converted to the map's key type. A
+// segment of decimal digits indexes into a slice or array, so
+// "cart.items.0.sku" reaches the first item; an index out of range is not
+// found. A key set to nil is found with a nil value, while a path continuing
+// below it is not found.
 func getValue(data map[string]any, path string) (any, bool) {
 	if data == nil {
 		return nil, false
@@ -162,17 +1015,91 @@ func getValue(data map[string]any, path string) (any, bool) {
 	parts := strings.Split(path, ".")
 	cur := any(data)
 	for _, p := range parts {
-		m, ok := cur.(map[string]any)
+		v, ok := child(cur, p)
 		if !ok {
 			return nil, false
 		}
-		v, ok := m[p]
+		cur = v
+	}
+	return cur, true
+}
+
+// child returns the entry keyed by key in the map cur, or the element at
+// index key in the slice or array cur.
+func child(cur any, key string) (any, bool) {
+	switch x := cur.(type) {
+	case map[string]any:
+		v, ok := x[key]
+		return v, ok
+	case []any:
+		i, ok := sliceIndex(key, len(x))
 		if !ok {
 			return nil, false
 		}
-		cur = v
+		return x[i], true
 	}
-	return cur, true
+	rv := reflect.ValueOf(cur)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		i, ok := sliceIndex(key, rv.Len())
+		if !ok {
+			return nil, false
+		}
+		return rv.Index(i).Interface(), true
+	}
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	k, ok := mapKey(rv.Type().Key(), key)
+	if !ok {
+		return nil, false
+	}
+	v := rv.MapIndex(k)
+	if !v.IsValid() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// sliceIndex parses a path segment of decimal digits as an index below n.
+func sliceIndex(key string, n int) (int, bool) {
+	if key == "" || strings.Trim(key, "0123456789") != "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i >= n {
+		return 0, false
+	}
+	return i, true
+}
+
+// mapKey converts a path segment to a map key of type t.
+func mapKey(t reflect.Type, s string) (reflect.Value, bool) {
+	k := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		k.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		k.SetBool(b)
+	default:
+		return reflect.Value{}, false
+	}
+	return k, true
 }
 
 // FromStruct converts a struct to map[string]any (uses JSON round-trip for simplicity and correctness).
@@ -189,18 +1116,52 @@ func FromStruct(s any) (map[string]any, error) {
 }
 
 // Helper comparison functions (pure, deterministic).
+
+// equal reports whether a and b are deeply equal, equal numbers, or the same
+// instant given as dates in any zone. Numeric strings are compared as numbers
+// and never as dates.
 func equal(a, b any) bool {
+	return equalTypes(a, b, false)
+}
+
+// equalTypes is equal, except that when strict, strings are never numbers,
+// so "1" does not equal 1.
+func equalTypes(a, b any, strict bool) bool {
 	if reflect.DeepEqual(a, b) {
 		return true
 	}
-	if fa, oka := toFloat(a); oka {
-		if fb, okb := toFloat(b); okb {
-			return fa == fb
+	fa, oka := toNumber(a, strict)
+	fb, okb := toNumber(b, strict)
+	if oka || okb {
+		return oka && okb && fa == fb
+	}
+	if ta, ok := toTime(a); ok {
+		if tb, ok := toTime(b); ok {
+			return ta.Equal(tb)
 		}
 	}
 	return false
 }
 
+// equalWithin reports whether a and b are equal, treating numbers within tol
+// of each other as equal. When strict, strings are never numbers.
+func equalWithin(a, b any, tol float64, strict bool) bool {
+	if equalTypes(a, b, strict) {
+		return true
+	}
+	fa, oka := toNumber(a, strict)
+	fb, okb := toNumber(b, strict)
+	return oka && okb && math.Abs(fa-fb) <= tol
+}
+
+// toNumber is toFloat, except that when strict, strings are not numbers.
+func toNumber(v any, strict bool) (float64, bool) {
+	if _, ok := v.(string); ok && strict {
+		return 0, false
+	}
+	return toFloat(v)
+}
+
 func toFloat(v any) (float64, bool) {
 	switch x := v.(type) {
 	case float64:
@@ -219,40 +1180,69 @@ func toFloat(v any) (float64, bool) {
 	return 0, false
 }
 
-func greater(a, b any) (bool, error) {
+// compare orders a and b numerically, or chronologically when both are dates
+// (time.Time values or RFC3339 strings). sym names the operator in errors.
+func compare(a, b any, sym string) (int, error) {
 	fa, oka := toFloat(a)
 	fb, okb := toFloat(b)
 	if oka && okb {
-		return fa > fb, nil
+		return cmp.Compare(fa, fb), nil
 	}
-	return false, fmt.Errorf("type mismatch for >")
+	ta, oka := toTime(a)
+	tb, okb := toTime(b)
+	switch {
+	case oka && okb:
+		return ta.Compare(tb), nil
+	case oka:
+		return 0, fmt.Errorf("cannot compare date with %v using %s: not an RFC3339 date", b, sym)
+	case okb:
+		return 0, fmt.Errorf("cannot compare %v with date using %s: not an RFC3339 date", a, sym)
+	}
+	return 0, typeMismatch(sym, a, b)
 }
 
-func greaterOrEqual(a, b any) (bool, error) {
-	fa, oka := toFloat(a)
-	fb, okb := toFloat(b)
-	if oka && okb {
-		return fa >= fb, nil
+// typeMismatch reports operands of the wrong type for op, wrapping
+// ErrNullOperand when one of them is null.
+func typeMismatch(op string, operands ...any) error {
+	for _, v := range operands {
+		if v == nil {
+			return fmt.Errorf("%w for %s", ErrNullOperand, op)
+		}
 	}
-	return false, fmt.Errorf("type mismatch for >=")
+	return fmt.Errorf("type mismatch for %s", op)
 }
 
-func less(a, b any) (bool, error) {
-	fa, oka := toFloat(a)
-	fb, okb := toFloat(b)
-	if oka && okb {
-		return fa < fb, nil
+// compareWithin is compare with numbers within tol of each other treated as
+// equal.
+func compareWithin(a, b any, sym string, tol float64) (int, error) {
+	if tol > 0 {
+		fa, oka := toFloat(a)
+		fb, okb := toFloat(b)
+		if oka && okb && math.Abs(fa-fb) <= tol {
+			return 0, nil
+		}
 	}
-	return false, fmt.Errorf("type mismatch for <")
+	return compare(a, b, sym)
 }
 
-func lessOrEqual(a, b any) (bool, error) {
-	fa, oka := toFloat(a)
-	fb, okb := toFloat(b)
-	if oka && okb {
-		return fa <= fb, nil
-	}
-	return false, fmt.Errorf("type mismatch for <=")
+func greater(a, b any, tol float64) (bool, error) {
+	c, err := compareWithin(a, b, ">", tol)
+	return c > 0, err
+}
+
+func greaterOrEqual(a, b any, tol float64) (bool, error) {
+	c, err := compareWithin(a, b, ">=", tol)
+	return c >= 0 && err == nil, err
+}
+
+func less(a, b any, tol float64) (bool, error) {
+	c, err := compareWithin(a, b, "<", tol)
+	return c < 0, err
+}
+
+func lessOrEqual(a, b any, tol float64) (bool, error) {
+	c, err := compareWithin(a, b, "<=", tol)
+	return c <= 0 && err == nil, err
 }
 
 func contains(a, b any) (bool, error) {
@@ -261,16 +1251,53 @@ func contains(a, b any) (bool, error) {
 			return strings.Contains(s, search), nil
 		}
 	}
-	return false, fmt.Errorf("type mismatch for contains")
+	return false, typeMismatch("contains", a, b)
+}
+
+func between(a, b any) (bool, error) {
+	bounds, err := betweenBounds(b)
+	if err != nil {
+		return false, err
+	}
+	lo, err := compare(a, bounds[0], "between")
+	if err != nil {
+		return false, err
+	}
+	hi, err := compare(a, bounds[1], "between")
+	if err != nil {
+		return false, err
+	}
+	return lo >= 0 && hi <= 0, nil
+}
+
+// betweenBounds checks that b is a [min, max] pair of numbers or dates.
+func betweenBounds(b any) ([]any, error) {
+	bounds, ok := b.([]any)
+	if !ok || len(bounds) != 2 {
+		return nil, fmt.Errorf("between requires [min, max] value")
+	}
+	for _, bound := range bounds {
+		_, isNum := toFloat(bound)
+		_, isDate := toTime(bound)
+		if !isNum && !isDate {
+			return nil, fmt.Errorf("between bound %v is not a number or date", bound)
+		}
+	}
+	return bounds, nil
+}
+
+func (e *Engine) notIn(a, b any) (bool, error) {
+	found, err := e.in(a, b)
+	return !found && err == nil, err
 }
 
-func in(a, b any) (bool, error) {
+func (e *Engine) in(a, b any) (bool, error) {
 	slice, ok := b.([]any)
 	if !ok {
 		return false, fmt.Errorf("in requires slice value")
 	}
 	for _, item := range slice {
-		if equal(a, item) {
+		if equalTypes(a, item, e.opts.StrictTypes) {
 			return true, nil
 		}
 	}
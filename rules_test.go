@@ -2,16 +2,21 @@ package rules
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestEvaluate(t *testing.T) {
 	tests := []struct {
-		name     string
-		rule     Rule
-		data     map[string]any
-		want     bool
-		wantErr  bool
+		name    string
+		rule    Rule
+		data    map[string]any
+		want    bool
+		wantErr bool
 	}{
 		{
 			name: "simple eq true",
@@ -54,6 +59,24 @@ func TestEvaluate(t *testing.T) {
 			data: map[string]any{"status": "pending"},
 			want: true,
 		},
+		{
+			name: "notin",
+			rule: Rule{Conditions: []Condition{{Field: "status", Op: OperatorNotIn, Value: []any{"active", "pending"}}}},
+			data: map[string]any{"status": "pending"},
+			want: false,
+		},
+		{
+			name: "notin absent value",
+			rule: Rule{Conditions: []Condition{{Field: "status", Op: OperatorNotIn, Value: []any{"active", "pending"}}}},
+			data: map[string]any{"status": "archived"},
+			want: true,
+		},
+		{
+			name:    "notin requires slice",
+			rule:    Rule{Conditions: []Condition{{Field: "status", Op: OperatorNotIn, Value: "active"}}},
+			data:    map[string]any{"status": "archived"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,8 +95,8 @@ func TestEvaluate(t *testing.T) {
 
 func TestFromStruct(t *testing.T) {
 	type User struct {
-		Age     int    `json:"age"`
-		Premium bool   `json:"premium"`
+		Age     int  `json:"age"`
+		Premium bool `json:"premium"`
 	}
 	u := User{Age: 25, Premium: true}
 	m, err := FromStruct(u)
@@ -84,3 +107,913 @@ func TestFromStruct(t *testing.T) {
 		t.Error("FromStruct failed")
 	}
 }
+
+func TestMemoizeContextualOperator(t *testing.T) {
+	for _, memoize := range []bool{false, true} {
+		e := New()
+		e.Memoize = memoize
+		calls := 0
+		e.RegisterContextual("slow-eq", func(_ context.Context, a, b any, _ map[string]any) (bool, error) {
+			calls++
+			return equal(a, b), nil
+		})
+		rule := Rule{Conditions: []Condition{
+			{Field: "tier", Op: "slow-eq", Value: "gold"},
+			{Field: "tier", Op: "slow-eq", Value: "gold"},
+			{Field: "tier", Op: OperatorNE, Value: "silver"},
+		}}
+		res, err := e.Evaluate(rule, map[string]any{"tier": "gold"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Matched {
+			t.Errorf("memoize=%v: expected match", memoize)
+		}
+		want := 2
+		if memoize {
+			want = 1
+		}
+		if calls != want {
+			t.Errorf("memoize=%v: operator called %d times, want %d", memoize, calls, want)
+		}
+	}
+}
+
+func TestGetValueNonStringKeys(t *testing.T) {
+	type region string
+	data := map[string]any{
+		"codes":   map[int]any{200: "ok", 404: "missing"},
+		"regions": map[region]any{"eu": map[string]any{"enabled": true}},
+		"flags":   map[bool]string{true: "on"},
+		"ports":   map[uint16]int{8080: 1},
+	}
+	tests := []struct {
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{path: "codes.200", want: "ok", wantOK: true},
+		{path: "codes.500", wantOK: false},
+		{path: "codes.abc", wantOK: false},
+		{path: "regions.eu.enabled", want: true, wantOK: true},
+		{path: "regions.us", wantOK: false},
+		{path: "flags.true", want: "on", wantOK: true},
+		{path: "ports.8080", want: 1, wantOK: true},
+		{path: "ports.70000", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := getValue(data, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rule := Rule{Conditions: []Condition{{Field: "codes.404", Op: OperatorEQ, Value: "missing"}}}
+	res, err := Evaluate(rule, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected rule on map[int]any field to match")
+	}
+}
+
+func TestGetValueSliceIndex(t *testing.T) {
+	data := map[string]any{
+		"cart": map[string]any{"items": []any{
+			map[string]any{"sku": "A-1", "price": 10.0},
+			map[string]any{"sku": "B-2", "price": 25.0},
+		}},
+		"matrix": []any{[]any{1, 2}, []any{3, 4}},
+		"scores": []int{7, 8, 9},
+		"pair":   [2]string{"x", "y"},
+		"name":   "alice",
+		"lookup": map[string]any{"0": "zero"},
+	}
+	tests := []struct {
+		path   string
+		want   any
+		wantOK bool
+	}{
+		{path: "cart.items.0.sku", want: "A-1", wantOK: true},
+		{path: "cart.items.1.price", want: 25.0, wantOK: true},
+		{path: "matrix.1.0", want: 3, wantOK: true},
+		{path: "scores.2", want: 9, wantOK: true},
+		{path: "pair.1", want: "y", wantOK: true},
+		{path: "lookup.0", want: "zero", wantOK: true},
+		{path: "cart.items.2.sku", wantOK: false},
+		{path: "cart.items.-1.sku", wantOK: false},
+		{path: "cart.items.+1.sku", wantOK: false},
+		{path: "cart.items.99999999999999999999", wantOK: false},
+		{path: "cart.items.first", wantOK: false},
+		{path: "scores.3", wantOK: false},
+		{path: "name.0", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := getValue(data, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rule := Rule{Conditions: []Condition{{Field: "cart.items.0.sku", Op: OperatorEQ, Value: "A-1"}}}
+	res, err := Evaluate(rule, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected rule on indexed field to match")
+	}
+}
+
+func TestValueField(t *testing.T) {
+	data := map[string]any{
+		"discount": 15, "subtotal": 120.0,
+		"billing":  map[string]any{"country": "FR"},
+		"shipping": map[string]any{"country": "fr"},
+		"owner":    "ada", "editor": "ada",
+	}
+	tests := []struct {
+		name     string
+		opts     Options
+		cond     Condition
+		want     bool
+		wantMiss bool
+	}{
+		{name: "numeric fields", cond: Condition{Field: "discount", Op: OperatorLT, ValueField: "subtotal"}, want: true},
+		{name: "numeric fields reversed", cond: Condition{Field: "subtotal", Op: OperatorLTE, ValueField: "discount"}, want: false},
+		{name: "string fields equal", cond: Condition{Field: "owner", Op: OperatorEQ, ValueField: "editor"}, want: true},
+		{name: "nested string fields differ", cond: Condition{Field: "billing.country", Op: OperatorNE, ValueField: "shipping.country"}, want: true},
+		{name: "field reference value", cond: Condition{Field: "discount", Op: OperatorLT, Value: "$field:subtotal"}, want: true},
+		{name: "missing field", cond: Condition{Field: "tax", Op: OperatorLT, ValueField: "subtotal"}, wantMiss: true},
+		{name: "missing value field", cond: Condition{Field: "discount", Op: OperatorLT, ValueField: "total"}, wantMiss: true},
+		{name: "missing value field as no match", opts: Options{MissingFieldBehavior: MissingAsNoMatch}, cond: Condition{Field: "discount", Op: OperatorLT, ValueField: "total"}, want: false},
+		{name: "negated missing value field as no match", opts: Options{MissingFieldBehavior: MissingAsNoMatch}, cond: Condition{Field: "discount", Op: OperatorLT, ValueField: "total", Negate: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewWithOptions(tt.opts).Evaluate(Rule{Conditions: []Condition{tt.cond}}, data)
+			if tt.wantMiss {
+				if !errors.Is(err, ErrFieldNotFound) {
+					t.Fatalf("error = %v, want ErrFieldNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v (%s)", res.Matched, tt.want, res.Explanation)
+			}
+		})
+	}
+}
+
+func TestValueFieldPercent(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "spend", Op: OperatorGTE, ValueField: "limit", Percent: 80}}}
+	tests := []struct {
+		name  string
+		spend any
+		want  bool
+	}{
+		{name: "below 80%", spend: 799, want: false},
+		{name: "at 80%", spend: 800, want: true},
+		{name: "above 80%", spend: 950.5, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(rule, map[string]any{"spend": tt.spend, "limit": 1000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v (%s)", res.Matched, tt.want, res.Explanation)
+			}
+		})
+	}
+
+	if _, err := Evaluate(rule, map[string]any{"spend": 1, "limit": "lots"}); err == nil {
+		t.Error("expected error for non-numeric limit")
+	}
+	if _, err := Evaluate(rule, map[string]any{"spend": 1}); err == nil {
+		t.Error("expected error for missing limit")
+	}
+}
+
+func TestTolerance(t *testing.T) {
+	e := New()
+	e.Tolerance = map[Operator]float64{OperatorEQ: 0.001, OperatorGTE: 0.5, OperatorGT: 0.5}
+	tests := []struct {
+		name  string
+		op    Operator
+		field any
+		value any
+		want  bool
+	}{
+		{name: "eq within tight tolerance", op: OperatorEQ, field: 0.1 + 0.2, value: 0.3, want: true},
+		{name: "eq outside tight tolerance", op: OperatorEQ, field: 9.6, value: 10, want: false},
+		{name: "gte within loose tolerance", op: OperatorGTE, field: 9.6, value: 10, want: true},
+		{name: "gte outside loose tolerance", op: OperatorGTE, field: 9.4, value: 10, want: false},
+		{name: "gt requires clearing tolerance", op: OperatorGT, field: 10.4, value: 10, want: false},
+		{name: "gt beyond tolerance", op: OperatorGT, field: 10.6, value: 10, want: true},
+		{name: "lt uses default zero", op: OperatorLT, field: 9.9999, value: 10, want: true},
+		{name: "ne uses default zero", op: OperatorNE, field: 10.0001, value: 10, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: tt.op, Value: tt.value}}}
+			res, err := e.Evaluate(rule, map[string]any{"v": tt.field})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackPrefix(t *testing.T) {
+	e := New()
+	e.FallbackPrefix = "defaults"
+	data := map[string]any{
+		"region":   "eu",
+		"defaults": map[string]any{"region": "us", "limits": map[string]any{"max": 10}},
+	}
+	tests := []struct {
+		name string
+		cond Condition
+		want bool
+	}{
+		{name: "primary path wins", cond: Condition{Field: "region", Op: OperatorEQ, Value: "eu"}, want: true},
+		{name: "nested path from fallback", cond: Condition{Field: "limits.max", Op: OperatorEQ, Value: 10}, want: true},
+		{name: "value field from fallback", cond: Condition{Field: "region", Op: OperatorNE, ValueField: "limits.max"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+
+	if _, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "tier", Op: OperatorEQ, Value: 1}}}, data); err == nil {
+		t.Error("expected error when field is missing from both namespaces")
+	}
+	if _, err := New().Evaluate(Rule{Conditions: []Condition{{Field: "limits.max", Op: OperatorEQ, Value: 10}}}, data); err == nil {
+		t.Error("expected error without a fallback prefix")
+	}
+}
+
+func TestResultHook(t *testing.T) {
+	e := New()
+	calls := 0
+	e.ResultHook = func(rule Rule, data map[string]any, res Result) Result {
+		calls++
+		if !res.Matched {
+			res.Explanation += "; action: request manual review"
+		}
+		return res
+	}
+	rule := Rule{Conditions: []Condition{
+		{Field: "score", Op: OperatorGTE, Value: 700},
+		{Field: "items", Op: OperatorAll, Value: Rule{Conditions: []Condition{{Field: "ok", Op: OperatorEQ, Value: true}}}},
+	}}
+	res, err := e.Evaluate(rule, map[string]any{"score": 640, "items": []any{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "score gte 700 → false; action: request manual review"; res.Explanation != want {
+		t.Errorf("Explanation = %q, want %q", res.Explanation, want)
+	}
+	res, err = e.Evaluate(rule, map[string]any{"score": 720, "items": []any{map[string]any{"ok": true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Explanation != "all conditions met" {
+		t.Errorf("Explanation = %q, hook should leave matches unchanged", res.Explanation)
+	}
+	if calls != 2 {
+		t.Errorf("hook called %d times, want 2 (once per top-level evaluation)", calls)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	e := New()
+	e.Register("explode", func(a, b any) (bool, error) {
+		return a.([]any)[5] == b, nil
+	})
+	rule := Rule{Conditions: []Condition{{Field: "v", Op: "explode", Value: 1}}}
+	data := map[string]any{"v": "not a slice"}
+
+	e.RecoverPanics = true
+	_, err := e.Evaluate(rule, data)
+	if err == nil {
+		t.Fatal("expected error from panicking operator")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `operator "explode" panicked`) || !strings.Contains(msg, "goroutine") {
+		t.Errorf("error = %q, want panic value and stack trace", msg)
+	}
+
+	e.RecoverPanics = false
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic to propagate with recovery off")
+		}
+	}()
+	e.Evaluate(rule, data)
+}
+
+func TestOpField(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "amount", OpField: "threshold_op", Value: 100}}}
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{"gt selected", map[string]any{"amount": 150, "threshold_op": "gt"}, true, false},
+		{"lt selected", map[string]any{"amount": 150, "threshold_op": "lt"}, false, false},
+		{"eq selected", map[string]any{"amount": 100, "threshold_op": "eq"}, true, false},
+		{"unknown operator", map[string]any{"amount": 100, "threshold_op": "approx"}, false, true},
+		{"non-string operator", map[string]any{"amount": 100, "threshold_op": 3}, false, true},
+		{"missing operator field", map[string]any{"amount": 100}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(rule, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+
+	// Op takes precedence over OpField.
+	res, err := Evaluate(Rule{Conditions: []Condition{{Field: "amount", Op: OperatorLT, OpField: "threshold_op", Value: 100}}}, map[string]any{"amount": 50, "threshold_op": "gt"})
+	if err != nil || !res.Matched {
+		t.Errorf("Evaluate() = %+v, %v; want Op to win", res, err)
+	}
+}
+
+func TestNegation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		data     map[string]any
+		want     bool
+		wantExpl string
+	}{
+		{
+			name:     "negated condition",
+			rule:     Rule{Conditions: []Condition{{Field: "age", Op: OperatorGT, Value: 18, Negate: true}}, Logic: LogicOR},
+			data:     map[string]any{"age": 16},
+			want:     true,
+			wantExpl: "NOT age gt 18 → true",
+		},
+		{
+			name:     "negated condition fails",
+			rule:     Rule{Conditions: []Condition{{Field: "age", Op: OperatorGT, Value: 18, Negate: true}}},
+			data:     map[string]any{"age": 30},
+			want:     false,
+			wantExpl: "NOT age gt 18 → false",
+		},
+		{
+			name:     "allow unless blocked",
+			rule:     Rule{Logic: LogicNOT, Conditions: []Condition{{Field: "blocked", Op: OperatorEQ, Value: true}}},
+			data:     map[string]any{"blocked": false},
+			want:     true,
+			wantExpl: "NOT (blocked eq true → false)",
+		},
+		{
+			name:     "not logic blocked",
+			rule:     Rule{Logic: LogicNOT, Conditions: []Condition{{Field: "blocked", Op: OperatorEQ, Value: true}}},
+			data:     map[string]any{"blocked": true},
+			want:     false,
+			wantExpl: "NOT (all conditions met)",
+		},
+		{
+			name: "not logic negates the conjunction",
+			rule: Rule{Logic: LogicNOT, Conditions: []Condition{
+				{Field: "country", Op: OperatorEQ, Value: "US"},
+				{Field: "age", Op: OperatorLT, Value: 21},
+			}},
+			data:     map[string]any{"country": "US", "age": 30},
+			want:     true,
+			wantExpl: "NOT (age lt 21 → false)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(tt.rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want || res.Explanation != tt.wantExpl {
+				t.Errorf("Evaluate() = %+v, want %v %q", res, tt.want, tt.wantExpl)
+			}
+		})
+	}
+}
+
+func TestRegisterVirtual(t *testing.T) {
+	e := New()
+	e.RegisterVirtual("full_name", func(data map[string]any) (any, error) {
+		first, _ := data["first"].(string)
+		last, _ := data["last"].(string)
+		return first + " " + last, nil
+	})
+	e.RegisterVirtual("broken", func(map[string]any) (any, error) {
+		return nil, fmt.Errorf("upstream unavailable")
+	})
+	rule := Rule{Conditions: []Condition{{Field: "full_name", Op: OperatorEQ, Value: "Ada Lovelace"}}}
+
+	res, err := e.Evaluate(rule, map[string]any{"first": "Ada", "last": "Lovelace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected virtual field to match")
+	}
+
+	res, err = e.Evaluate(rule, map[string]any{"first": "Ada", "last": "King", "full_name": "Ada Lovelace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("data should take precedence over the virtual field")
+	}
+
+	ref := Rule{Conditions: []Condition{{Field: "display", Op: OperatorEQ, Value: "$field:full_name"}}}
+	if res, err := e.Evaluate(ref, map[string]any{"first": "A", "last": "B", "display": "A B"}); err != nil || !res.Matched {
+		t.Errorf("Evaluate() = %+v, %v; want virtual field reference to resolve", res, err)
+	}
+
+	if _, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "broken", Op: OperatorEQ, Value: 1}}}, map[string]any{}); err == nil || !strings.Contains(err.Error(), "upstream unavailable") {
+		t.Errorf("error = %v, want virtual field error", err)
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name  string
+		cond  Condition
+		field any
+	}{
+		{"eq", Condition{Field: "v", Op: OperatorEQ, Value: "active"}, "Active"},
+		{"ne", Condition{Field: "v", Op: OperatorNE, Value: "ACTIVE", Negate: true}, "active"},
+		{"contains", Condition{Field: "v", Op: OperatorContains, Value: "ACT"}, "Active"},
+		{"in", Condition{Field: "v", Op: OperatorIn, Value: []any{"us", "ca"}}, "US"},
+		{"startswith", Condition{Field: "v", Op: OperatorStartsWith, Value: "sku-"}, "SKU-1"},
+		{"endswith", Condition{Field: "v", Op: OperatorEndsWith, Value: ".PDF"}, "report.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{tt.cond}}
+			data := map[string]any{"v": tt.field}
+			res, err := New().Evaluate(rule, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched {
+				t.Error("default engine should be case-sensitive")
+			}
+			res, err = NewWithOptions(Options{CaseInsensitive: true}).Evaluate(rule, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Matched {
+				t.Error("case-insensitive engine should match")
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveNonStrings(t *testing.T) {
+	e := NewWithOptions(Options{CaseInsensitive: true})
+	tests := []struct {
+		cond  Condition
+		field any
+		want  bool
+	}{
+		{Condition{Field: "v", Op: OperatorEQ, Value: 1}, 1.0, true},
+		{Condition{Field: "v", Op: OperatorEQ, Value: true}, true, true},
+		{Condition{Field: "v", Op: OperatorIn, Value: []any{1, 2}}, 3, false},
+		{Condition{Field: "v", Op: OperatorGT, Value: "2026-01-01"}, "2026-02-01", true},
+	}
+	for _, tt := range tests {
+		res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, map[string]any{"v": tt.field})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != tt.want {
+			t.Errorf("%v %s %v: Matched = %v, want %v", tt.field, tt.cond.Op, tt.cond.Value, res.Matched, tt.want)
+		}
+	}
+}
+
+func TestNotInExplanation(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "status", Op: OperatorNotIn, Value: []any{"active", "pending"}}}, Logic: LogicOR}
+	res, err := Evaluate(rule, map[string]any{"status": "archived"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "status notin [active pending] → true"; res.Explanation != want {
+		t.Errorf("Explanation = %q, want %q", res.Explanation, want)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "in range", field: 30, value: []any{18, 65}, want: true},
+		{name: "equal to min", field: 18, value: []any{18, 65}, want: true},
+		{name: "equal to max", field: 65.0, value: []any{18, 65}, want: true},
+		{name: "below range", field: 17.99, value: []any{18, 65}, want: false},
+		{name: "above range", field: 66, value: []any{18, 65}, want: false},
+		{name: "numeric strings", field: "19.5", value: []any{"10", "20"}, want: true},
+		{name: "date in range", field: "2026-03-15", value: []any{"2026-03-01", "2026-03-31"}, want: true},
+		{name: "date out of range", field: "2026-04-01T00:00:00Z", value: []any{"2026-03-01", "2026-03-31"}, want: false},
+		{name: "not a slice", field: 1, value: 5, wantErr: true},
+		{name: "wrong length", field: 1, value: []any{1, 2, 3}, wantErr: true},
+		{name: "non-numeric bound", field: 1, value: []any{"low", 2}, wantErr: true},
+		{name: "non-numeric field", field: "old", value: []any{1, 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorBetween, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	pointer := New()
+	pointer.JSONPointer = true
+	tests := []struct {
+		name   string
+		engine *Engine
+		cond   Condition
+		data   map[string]any
+		want   error
+	}{
+		{name: "missing field", cond: Condition{Field: "age", Op: OperatorGT, Value: 18}, data: map[string]any{}, want: ErrFieldNotFound},
+		{name: "missing nested field", cond: Condition{Field: "user.age", Op: OperatorGT, Value: 18}, data: map[string]any{"user": map[string]any{}}, want: ErrFieldNotFound},
+		{name: "missing field as JSON pointer", engine: pointer, cond: Condition{Field: "user.age", Op: OperatorGT, Value: 18}, data: map[string]any{}, want: ErrFieldNotFound},
+		{name: "missing value field", cond: Condition{Field: "a", Op: OperatorEQ, ValueField: "b"}, data: map[string]any{"a": 1}, want: ErrFieldNotFound},
+		{name: "missing $field reference", cond: Condition{Field: "a", Op: OperatorEQ, Value: "$field:b"}, data: map[string]any{"a": 1}, want: ErrFieldNotFound},
+		{name: "unknown operator", cond: Condition{Field: "a", Op: "approx", Value: 1}, data: map[string]any{"a": 1}, want: ErrUnknownOperator},
+		{name: "unknown operator from field", cond: Condition{Field: "a", OpField: "op", Value: 1}, data: map[string]any{"a": 1, "op": "approx"}, want: ErrUnknownOperator},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.engine
+			if e == nil {
+				e = New()
+			}
+			_, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want errors.Is %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("composed operator", func(t *testing.T) {
+		_, err := New().Compose(func(v any) (any, error) { return v, nil }, "approx")(1, 1)
+		if !errors.Is(err, ErrUnknownOperator) {
+			t.Errorf("error = %v, want errors.Is ErrUnknownOperator", err)
+		}
+	})
+	t.Run("distinct", func(t *testing.T) {
+		_, err := Evaluate(Rule{Conditions: []Condition{{Field: "a", Op: OperatorGT, Value: "x"}}}, map[string]any{"a": 1})
+		if err == nil || errors.Is(err, ErrFieldNotFound) || errors.Is(err, ErrUnknownOperator) {
+			t.Errorf("type mismatch error = %v, want neither sentinel", err)
+		}
+	})
+}
+
+func TestMissingFieldBehavior(t *testing.T) {
+	lenient := NewWithOptions(Options{MissingFieldBehavior: MissingAsNoMatch})
+	tests := []struct {
+		name     string
+		rule     Rule
+		data     map[string]any
+		want     bool
+		wantExpl string
+	}{
+		{
+			name:     "missing field does not match",
+			rule:     Rule{Conditions: []Condition{{Field: "beta", Op: OperatorEQ, Value: true}}},
+			data:     map[string]any{},
+			wantExpl: `beta eq true: field "beta" missing → false`,
+		},
+		{
+			name:     "negated missing field does not match",
+			rule:     Rule{Conditions: []Condition{{Field: "beta", Op: OperatorEQ, Value: true, Negate: true}}},
+			data:     map[string]any{},
+			wantExpl: `beta eq true: field "beta" missing → false`,
+		},
+		{
+			name:     "missing value field",
+			rule:     Rule{Conditions: []Condition{{Field: "spent", Op: OperatorLT, ValueField: "budget"}}},
+			data:     map[string]any{"spent": 10},
+			wantExpl: `spent lt $field:budget: field "budget" missing → false`,
+		},
+		{
+			name: "OR falls through to a present field",
+			rule: Rule{Logic: LogicOR, Conditions: []Condition{
+				{Field: "beta", Op: OperatorEQ, Value: true},
+				{Field: "plan", Op: OperatorEQ, Value: "pro"},
+			}},
+			data:     map[string]any{"plan": "pro"},
+			want:     true,
+			wantExpl: "plan eq pro → true",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := lenient.Evaluate(tt.rule, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want || res.Explanation != tt.wantExpl {
+				t.Errorf("got %v %q, want %v %q", res.Matched, res.Explanation, tt.want, tt.wantExpl)
+			}
+			if _, err := New().Evaluate(tt.rule, tt.data); !errors.Is(err, ErrFieldNotFound) && !tt.want {
+				t.Errorf("default engine error = %v, want ErrFieldNotFound", err)
+			}
+		})
+	}
+
+	t.Run("other errors still fail", func(t *testing.T) {
+		rule := Rule{Conditions: []Condition{{Field: "age", Op: OperatorGT, Value: "old"}}}
+		if _, err := lenient.Evaluate(rule, map[string]any{"age": 30}); err == nil {
+			t.Error("expected type mismatch error")
+		}
+	})
+}
+
+// TestConcurrentRegistration is meaningful under go test -race.
+func TestConcurrentRegistration(t *testing.T) {
+	e := New()
+	rule := Rule{Conditions: []Condition{
+		{Field: "n", Op: OperatorGT, Value: 1},
+		{Field: "name", Op: OperatorEQ, Value: "ADA", Transform: "upper"},
+	}}
+	data := map[string]any{"n": 2, "name": "ada"}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			op := Operator(fmt.Sprintf("custom%d", i))
+			e.Register(op, func(a, b any) (bool, error) { return equal(a, b), nil })
+			e.RegisterContextual(op+"_ctx", func(_ context.Context, a, b any, _ map[string]any) (bool, error) { return equal(a, b), nil })
+			e.RegisterTransform(fmt.Sprintf("t%d", i), func(v any) (any, error) { return v, nil })
+			e.RegisterVirtual(fmt.Sprintf("v%d", i), func(map[string]any) (any, error) { return i, nil })
+			e.RegisterEnum(fmt.Sprintf("e%d", i), []string{"a"})
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				res, err := e.Evaluate(rule, data)
+				if err != nil || !res.Matched {
+					t.Errorf("got %v, %v; want match", res.Matched, err)
+					return
+				}
+				if err := e.Validate(rule); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "v3", Op: "custom3_ctx", Value: 3}}}, data)
+	if err != nil || !res.Matched {
+		t.Errorf("got %v, %v; want registered operator and virtual field to match", res.Matched, err)
+	}
+}
+
+func TestMaxDuration(t *testing.T) {
+	e := New()
+	e.MaxDuration = 20 * time.Millisecond
+	e.RegisterContextual("slow", func(ctx context.Context, _, _ any, _ map[string]any) (bool, error) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(time.Second):
+			return true, nil
+		}
+	})
+	e.Register("stubborn", func(_, _ any) (bool, error) {
+		time.Sleep(40 * time.Millisecond)
+		return true, nil
+	})
+	data := map[string]any{"a": 1}
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr error
+	}{
+		{name: "fast rule within budget", rule: Rule{Conditions: []Condition{{Field: "a", Op: OperatorEQ, Value: 1}}}},
+		{name: "budget interrupts contextual operator", rule: Rule{Conditions: []Condition{{Field: "a", Op: "slow"}}}, wantErr: ErrMaxDuration},
+		{name: "result past budget discarded", rule: Rule{Conditions: []Condition{{Field: "a", Op: "stubborn"}}}, wantErr: ErrMaxDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			res, err := e.Evaluate(tt.rule, data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !res.Matched {
+				t.Errorf("expected match: %s", res.Explanation)
+			}
+			if tt.wantErr != nil && !errors.Is(err, context.DeadlineExceeded) {
+				t.Error("ErrMaxDuration should wrap context.DeadlineExceeded")
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("evaluation took %v", elapsed)
+			}
+		})
+	}
+	t.Run("caller deadline reported as is", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		e := New()
+		e.MaxDuration = time.Minute
+		e.RegisterContextual("slow", func(ctx context.Context, _, _ any, _ map[string]any) (bool, error) {
+			<-ctx.Done()
+			return false, ctx.Err()
+		})
+		_, err := e.EvaluateWithContext(ctx, Rule{Conditions: []Condition{{Field: "a", Op: "slow"}}}, data)
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrMaxDuration) {
+			t.Errorf("error = %v, want the caller's deadline", err)
+		}
+	})
+}
+
+func TestNullHandling(t *testing.T) {
+	data := map[string]any{"x": nil, "n": 5}
+	tests := []struct {
+		name     string
+		cond     Condition
+		want     bool
+		wantNull bool // error wraps ErrNullOperand
+		wantMiss bool // error wraps ErrFieldNotFound
+	}{
+		{name: "eq null", cond: Condition{Field: "x", Op: OperatorEQ, Value: nil}, want: true},
+		{name: "eq value", cond: Condition{Field: "x", Op: OperatorEQ, Value: 0}, want: false},
+		{name: "eq empty string", cond: Condition{Field: "x", Op: OperatorEQ, Value: ""}, want: false},
+		{name: "non-null field eq null", cond: Condition{Field: "n", Op: OperatorEQ, Value: nil}, want: false},
+		{name: "ne null", cond: Condition{Field: "x", Op: OperatorNE, Value: nil}, want: false},
+		{name: "ne value", cond: Condition{Field: "x", Op: OperatorNE, Value: 1}, want: true},
+		{name: "in list with null", cond: Condition{Field: "x", Op: OperatorIn, Value: []any{nil, 1}}, want: true},
+		{name: "notin list without null", cond: Condition{Field: "x", Op: OperatorNotIn, Value: []any{1, 2}}, want: true},
+		{name: "exists", cond: Condition{Field: "x", Op: OperatorExists}, want: true},
+		{name: "gt null field", cond: Condition{Field: "x", Op: OperatorGT, Value: 1}, wantNull: true},
+		{name: "lt null value", cond: Condition{Field: "n", Op: OperatorLT, Value: nil}, wantNull: true},
+		{name: "between null field", cond: Condition{Field: "x", Op: OperatorBetween, Value: []any{1, 2}}, wantNull: true},
+		{name: "contains null field", cond: Condition{Field: "x", Op: OperatorContains, Value: "a"}, wantNull: true},
+		{name: "startswith null", cond: Condition{Field: "x", Op: OperatorStartsWith, Value: "a"}, wantNull: true},
+		{name: "regex null", cond: Condition{Field: "x", Op: OperatorRegex, Value: "a"}, wantNull: true},
+		{name: "len null", cond: Condition{Field: "x", Op: OperatorLen, Value: map[string]any{"op": "gt", "value": 0}}, wantNull: true},
+		{name: "path below null", cond: Condition{Field: "x.y", Op: OperatorEQ, Value: nil}, wantMiss: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Evaluate(Rule{Conditions: []Condition{tt.cond}}, data)
+			switch {
+			case tt.wantNull:
+				if !errors.Is(err, ErrNullOperand) {
+					t.Fatalf("error = %v, want ErrNullOperand", err)
+				}
+			case tt.wantMiss:
+				if !errors.Is(err, ErrFieldNotFound) {
+					t.Fatalf("error = %v, want ErrFieldNotFound", err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextualOperatorReadsData(t *testing.T) {
+	e := New()
+	// near_average matches a field within condVal percent of the mean of the
+	// "history" field.
+	e.RegisterContextual("near_average", func(_ context.Context, fieldVal, condVal any, data map[string]any) (bool, error) {
+		history, ok := data["history"].([]any)
+		if !ok || len(history) == 0 {
+			return false, errors.New("near_average requires a non-empty history field")
+		}
+		var sum float64
+		for _, h := range history {
+			f, _ := toFloat(h)
+			sum += f
+		}
+		mean := sum / float64(len(history))
+		v, _ := toFloat(fieldVal)
+		pct, _ := toFloat(condVal)
+		return v >= mean*(1-pct/100) && v <= mean*(1+pct/100), nil
+	})
+	rule := Rule{Conditions: []Condition{{Field: "amount", Op: "near_average", Value: 10}}}
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{name: "within band", data: map[string]any{"amount": 105, "history": []any{90, 100, 110}}, want: true},
+		{name: "outside band", data: map[string]any{"amount": 150, "history": []any{90, 100, 110}}, want: false},
+		{name: "second field missing", data: map[string]any{"amount": 100}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(rule, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictTypes(t *testing.T) {
+	strict := NewWithOptions(Options{StrictTypes: true})
+	lenient := New()
+	tests := []struct {
+		name        string
+		cond        Condition
+		field       any
+		wantStrict  bool
+		wantLenient bool
+	}{
+		{name: "string vs number", cond: Condition{Field: "v", Op: OperatorEQ, Value: 1}, field: "1", wantStrict: false, wantLenient: true},
+		{name: "number vs string", cond: Condition{Field: "v", Op: OperatorEQ, Value: "1"}, field: 1, wantStrict: false, wantLenient: true},
+		{name: "ne string vs number", cond: Condition{Field: "v", Op: OperatorNE, Value: 1}, field: "1", wantStrict: true, wantLenient: false},
+		{name: "int vs float", cond: Condition{Field: "v", Op: OperatorEQ, Value: 1.0}, field: 1, wantStrict: true, wantLenient: true},
+		{name: "float64 vs float32", cond: Condition{Field: "v", Op: OperatorEQ, Value: float32(2.5)}, field: 2.5, wantStrict: true, wantLenient: true},
+		{name: "numeric strings", cond: Condition{Field: "v", Op: OperatorEQ, Value: "1.0"}, field: "1", wantStrict: false, wantLenient: true},
+		{name: "same strings", cond: Condition{Field: "v", Op: OperatorEQ, Value: "1"}, field: "1", wantStrict: true, wantLenient: true},
+		{name: "bool vs bool", cond: Condition{Field: "v", Op: OperatorEQ, Value: true}, field: true, wantStrict: true, wantLenient: true},
+		{name: "bool vs string", cond: Condition{Field: "v", Op: OperatorEQ, Value: "true"}, field: true, wantStrict: false, wantLenient: false},
+		{name: "bool vs number", cond: Condition{Field: "v", Op: OperatorEQ, Value: 1}, field: true, wantStrict: false, wantLenient: false},
+		{name: "in with string", cond: Condition{Field: "v", Op: OperatorIn, Value: []any{1, 2}}, field: "2", wantStrict: false, wantLenient: true},
+		{name: "in with float", cond: Condition{Field: "v", Op: OperatorIn, Value: []any{1, 2}}, field: 2.0, wantStrict: true, wantLenient: true},
+		{name: "notin with string", cond: Condition{Field: "v", Op: OperatorNotIn, Value: []any{1, 2}}, field: "2", wantStrict: true, wantLenient: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{tt.cond}}
+			data := map[string]any{"v": tt.field}
+			for _, mode := range []struct {
+				e    *Engine
+				want bool
+			}{{strict, tt.wantStrict}, {lenient, tt.wantLenient}} {
+				res, err := mode.e.Evaluate(rule, data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Matched != mode.want {
+					t.Errorf("StrictTypes %v: Matched = %v, want %v", mode.e.opts.StrictTypes, res.Matched, mode.want)
+				}
+			}
+		})
+	}
+}
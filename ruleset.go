@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// NamedRule is a rule identified by name within a RuleSet. Rules with a
+// higher Priority are considered first.
+type NamedRule struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority,omitempty"`
+	Rule     Rule   `json:"rule"`
+}
+
+// RuleSet is a collection of named rules.
+type RuleSet struct {
+	Rules []NamedRule `json:"rules"`
+}
+
+// byPriority returns the set's rules ordered by descending priority, keeping
+// the set's order among equal priorities.
+func (s RuleSet) byPriority() []NamedRule {
+	ordered := slices.Clone(s.Rules)
+	slices.SortStableFunc(ordered, func(a, b NamedRule) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	return ordered
+}
+
+// TopMatches returns the names of up to n rules of set matching data, in
+// priority order and in set order among equal priorities. Evaluation stops
+// once n rules have matched.
+func (e *Engine) TopMatches(set RuleSet, data map[string]any, n int) ([]string, error) {
+	return e.TopMatchesContext(context.Background(), set, data, n)
+}
+
+// TopMatchesContext is TopMatches with a context for the evaluations.
+func (e *Engine) TopMatchesContext(ctx context.Context, set RuleSet, data map[string]any, n int) ([]string, error) {
+	var names []string
+	for _, r := range set.byPriority() {
+		if len(names) >= n {
+			break
+		}
+		res, err := e.EvaluateWithContext(ctx, r.Rule, data)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		if res.Matched {
+			names = append(names, r.Name)
+		}
+	}
+	return names, nil
+}
+
+// EvaluateFirst evaluates the rules of set against data in priority order,
+// and in set order among equal priorities, stopping at the first match:
+// "first match wins", as in firewall rules. When no rule sets a Priority,
+// that is simply the set's order. It returns the matching rule's name and
+// result; when no rule matches, the name is empty.
+func (e *Engine) EvaluateFirst(ctx context.Context, set RuleSet, data map[string]any) (string, Result, error) {
+	for _, r := range set.byPriority() {
+		res, err := e.EvaluateWithContext(ctx, r.Rule, data)
+		if err != nil {
+			return "", Result{}, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		if res.Matched {
+			return r.Name, res, nil
+		}
+	}
+	return "", Result{Explanation: "no rule matched"}, nil
+}
+
+// EvaluateFirst uses the default engine.
+func (s RuleSet) EvaluateFirst(ctx context.Context, data map[string]any) (string, Result, error) {
+	return Default.EvaluateFirst(ctx, s, data)
+}
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTopMatches(t *testing.T) {
+	gt := func(v int) Rule { return Rule{Conditions: []Condition{{Field: "score", Op: OperatorGT, Value: v}}} }
+	set := RuleSet{Rules: []NamedRule{
+		{Name: "low", Priority: 1, Rule: gt(10)},
+		{Name: "high", Priority: 10, Rule: gt(90)},
+		{Name: "mid", Priority: 5, Rule: gt(50)},
+		{Name: "mid-tie", Priority: 5, Rule: gt(40)},
+		{Name: "never", Priority: 20, Rule: gt(1000)},
+	}}
+	data := map[string]any{"score": 60}
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"fewer than matches", 2, []string{"mid", "mid-tie"}},
+		{"equal to matches", 3, []string{"mid", "mid-tie", "low"}},
+		{"more than matches", 10, []string{"mid", "mid-tie", "low"}},
+		{"zero", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().TopMatches(set, data, tt.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("TopMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopMatchesError(t *testing.T) {
+	set := RuleSet{Rules: []NamedRule{{Name: "bad", Rule: Rule{Conditions: []Condition{{Field: "missing", Op: OperatorEQ, Value: 1}}}}}}
+	if _, err := New().TopMatches(set, map[string]any{}, 1); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestTopMatchesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	set := RuleSet{Rules: []NamedRule{{Name: "a", Rule: Rule{Conditions: []Condition{{Field: "x", Op: OperatorEQ, Value: 1}}}}}}
+	if _, err := New().TopMatchesContext(ctx, set, map[string]any{"x": 1}, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestEvaluateFirst(t *testing.T) {
+	set := RuleSet{Rules: []NamedRule{
+		{Name: "block-country", Rule: Rule{Conditions: []Condition{{Field: "country", Op: OperatorIn, Value: []any{"KP", "IR"}}}}},
+		{Name: "allow-admin", Rule: Rule{Conditions: []Condition{{Field: "role", Op: OperatorEQ, Value: "admin"}}}},
+		{Name: "allow-all", Rule: Rule{}},
+	}}
+	tests := []struct {
+		name     string
+		set      RuleSet
+		data     map[string]any
+		want     string
+		wantExpl string
+	}{
+		{name: "second matches", set: set, data: map[string]any{"country": "US", "role": "admin"}, want: "allow-admin", wantExpl: "all conditions met"},
+		{name: "first wins over later matches", set: set, data: map[string]any{"country": "KP", "role": "admin"}, want: "block-country", wantExpl: "all conditions met"},
+		{name: "catch-all", set: set, data: map[string]any{"country": "US", "role": "user"}, want: "allow-all"},
+		{name: "no match", set: RuleSet{Rules: set.Rules[:2]}, data: map[string]any{"country": "US", "role": "user"}, wantExpl: "no rule matched"},
+		{
+			name: "priority before order",
+			set: RuleSet{Rules: []NamedRule{
+				{Name: "first", Rule: Rule{}},
+				{Name: "urgent", Priority: 1, Rule: Rule{}},
+			}},
+			want: "urgent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, res, err := tt.set.EvaluateFirst(context.Background(), tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.want || res.Matched != (tt.want != "") || res.Explanation != tt.wantExpl {
+				t.Errorf("got %q %+v, want %q %q", name, res, tt.want, tt.wantExpl)
+			}
+		})
+	}
+
+	t.Run("error names the rule", func(t *testing.T) {
+		_, _, err := New().EvaluateFirst(context.Background(), set, map[string]any{})
+		if !errors.Is(err, ErrFieldNotFound) || !strings.Contains(err.Error(), `rule "block-country"`) {
+			t.Errorf("error = %v, want block-country ErrFieldNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+)
+
+// OperatorMatchesSchema matches a field conforming to a JSON Schema
+// registered with RegisterSchema; the condition value is the schema's name.
+// The field may be a map or other decoded JSON value, or a string holding
+// JSON, which does not match when it fails to parse.
+const OperatorMatchesSchema Operator = "matches_schema"
+
+// jsonSchema is the subset of JSON Schema understood by
+// OperatorMatchesSchema.
+type jsonSchema struct {
+	Type                 any                    `json:"type"` // a type name or a list of them
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []any                  `json:"enum"`
+	Const                json.RawMessage        `json:"const"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+
+	// Derived by compile.
+	types        []string
+	constVal     any
+	pattern      *regexp.Regexp
+	noAdditional bool
+	additional   *jsonSchema
+}
+
+// RegisterSchema registers a JSON Schema for use with OperatorMatchesSchema,
+// replacing any schema previously registered under name. The validator is
+// minimal: it supports type, properties, required, additionalProperties,
+// items, enum, const, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// minLength, maxLength, pattern, minItems and maxItems, and ignores other
+// keywords such as $ref and the combinators.
+func (e *Engine) RegisterSchema(name string, schema []byte) error {
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("schema %q: %w", name, err)
+	}
+	if err := s.compile(); err != nil {
+		return fmt.Errorf("schema %q: %w", name, err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.schemas[name] = &s
+	return nil
+}
+
+// compile derives the fields used during validation for s and its
+// subschemas.
+func (s *jsonSchema) compile() error {
+	switch t := s.Type.(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, name := range t {
+			n, ok := name.(string)
+			if !ok {
+				return fmt.Errorf("type must be a string or list of strings")
+			}
+			s.types = append(s.types, n)
+		}
+	default:
+		return fmt.Errorf("type must be a string or list of strings")
+	}
+	if s.Const != nil {
+		if err := json.Unmarshal(s.Const, &s.constVal); err != nil {
+			return err
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	switch string(s.AdditionalProperties) {
+	case "", "true":
+	case "false":
+		s.noAdditional = true
+	default:
+		s.additional = new(jsonSchema)
+		if err := json.Unmarshal(s.AdditionalProperties, s.additional); err != nil {
+			return fmt.Errorf("additionalProperties: %w", err)
+		}
+		if err := s.additional.compile(); err != nil {
+			return err
+		}
+	}
+	for name, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return fmt.Errorf("property %q: %w", name, err)
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(); err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) matchesSchema(a, b any) (bool, error) {
+	name, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("matches_schema requires a schema name value")
+	}
+	e.mu.RLock()
+	s, ok := e.schemas[name]
+	e.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("unknown schema %q", name)
+	}
+	if str, ok := a.(string); ok {
+		var doc any
+		if err := json.Unmarshal([]byte(str), &doc); err != nil {
+			return false, nil
+		}
+		a = doc
+	}
+	return s.valid(a), nil
+}
+
+// valid reports whether v conforms to s.
+func (s *jsonSchema) valid(v any) bool {
+	if len(s.types) > 0 && !hasSchemaType(s.types, v) {
+		return false
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(item any) bool { return jsonEqual(v, item) }) {
+		return false
+	}
+	if s.Const != nil && !jsonEqual(v, s.constVal) {
+		return false
+	}
+	switch x := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(x)
+		if s.MinLength != nil && n < *s.MinLength || s.MaxLength != nil && n > *s.MaxLength {
+			return false
+		}
+		if s.pattern != nil && !s.pattern.MatchString(x) {
+			return false
+		}
+	case []any:
+		if s.MinItems != nil && len(x) < *s.MinItems || s.MaxItems != nil && len(x) > *s.MaxItems {
+			return false
+		}
+		if s.Items != nil {
+			for _, item := range x {
+				if !s.Items.valid(item) {
+					return false
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := x[name]; !ok {
+				return false
+			}
+		}
+		for k, item := range x {
+			p, ok := s.Properties[k]
+			switch {
+			case ok:
+				if !p.valid(item) {
+					return false
+				}
+			case s.noAdditional:
+				return false
+			case s.additional != nil:
+				if !s.additional.valid(item) {
+					return false
+				}
+			}
+		}
+	case bool, nil:
+	default:
+		f, ok := toFloat(v)
+		if !ok {
+			return false
+		}
+		if s.Minimum != nil && f < *s.Minimum || s.Maximum != nil && f > *s.Maximum ||
+			s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum || s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSchemaType reports whether v is of one of the JSON Schema types.
+// jsonEqual reports whether a and b are the same JSON value, as enum and
+// const compare them: numbers by value whatever their Go type, and every
+// other value only with one of its own kind, so "5" does not equal 5.
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case nil:
+		return b == nil
+	case string, bool:
+		return a == b
+	case []any:
+		y, ok := b.([]any)
+		return ok && slices.EqualFunc(x, y, jsonEqual)
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			if yv, ok := y[k]; !ok || !jsonEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	if _, isString := b.(string); isString {
+		return false
+	}
+	fa, oka := toFloat(a)
+	fb, okb := toFloat(b)
+	return oka && okb && fa == fb
+}
+
+func hasSchemaType(types []string, v any) bool {
+	for _, t := range types {
+		var ok bool
+		switch t {
+		case "object":
+			_, ok = v.(map[string]any)
+		case "array":
+			_, ok = v.([]any)
+		case "string":
+			_, ok = v.(string)
+		case "boolean":
+			_, ok = v.(bool)
+		case "null":
+			ok = v == nil
+		case "number", "integer":
+			if _, isString := v.(string); !isString {
+				var f float64
+				f, ok = toFloat(v)
+				ok = ok && (t == "number" || f == math.Trunc(f))
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package rules
+
+import "testing"
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["id", "items"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "pattern": "^ord-[0-9]+$"},
+		"status": {"enum": ["pending", "paid"]},
+		"total": {"type": "number", "minimum": 0},
+		"coupon": {"type": ["string", "null"], "maxLength": 8},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"required": ["sku", "qty"],
+				"properties": {"sku": {"type": "string", "minLength": 1}, "qty": {"type": "integer", "exclusiveMinimum": 0}}
+			}
+		}
+	}
+}`
+
+func TestMatchesSchema(t *testing.T) {
+	e := New()
+	if err := e.RegisterSchema("order", []byte(orderSchema)); err != nil {
+		t.Fatal(err)
+	}
+	item := map[string]any{"sku": "A1", "qty": 2}
+	tests := []struct {
+		name    string
+		body    any
+		schema  string
+		want    bool
+		wantErr bool
+	}{
+		{name: "conforming map", body: map[string]any{"id": "ord-1", "items": []any{item}, "total": 9.5, "status": "paid"}, want: true},
+		{name: "conforming JSON string", body: `{"id":"ord-42","items":[{"sku":"B2","qty":1}],"coupon":null}`, want: true},
+		{name: "missing required", body: map[string]any{"id": "ord-1"}, want: false},
+		{name: "pattern mismatch", body: map[string]any{"id": "order-1", "items": []any{item}}, want: false},
+		{name: "enum mismatch", body: map[string]any{"id": "ord-1", "items": []any{item}, "status": "lost"}, want: false},
+		{name: "below minimum", body: map[string]any{"id": "ord-1", "items": []any{item}, "total": -1}, want: false},
+		{name: "wrong type", body: map[string]any{"id": 1, "items": []any{item}}, want: false},
+		{name: "too long", body: map[string]any{"id": "ord-1", "items": []any{item}, "coupon": "WELCOME-2024"}, want: false},
+		{name: "additional property", body: map[string]any{"id": "ord-1", "items": []any{item}, "note": "x"}, want: false},
+		{name: "empty items", body: map[string]any{"id": "ord-1", "items": []any{}}, want: false},
+		{name: "invalid item", body: `{"id":"ord-1","items":[{"sku":"A1","qty":1.5}]}`, want: false},
+		{name: "non-positive qty", body: `{"id":"ord-1","items":[{"sku":"A1","qty":0}]}`, want: false},
+		{name: "not an object", body: `[1, 2]`, want: false},
+		{name: "invalid JSON", body: `{"id":`, want: false},
+		{name: "unknown schema", body: map[string]any{}, schema: "invoice", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := tt.schema
+			if schema == "" {
+				schema = "order"
+			}
+			rule := Rule{Conditions: []Condition{{Field: "body", Op: OperatorMatchesSchema, Value: schema}}}
+			res, err := e.Evaluate(rule, map[string]any{"body": tt.body})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesSchemaStrictEquality(t *testing.T) {
+	e := New()
+	if err := e.RegisterSchema("five", []byte(`{"properties": {"v": {"const": 5}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RegisterSchema("small", []byte(`{"properties": {"v": {"enum": [1, 2, [3], {"n": 4}]}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		schema string
+		value  any
+		want   bool
+	}{
+		{name: "const number", schema: "five", value: 5, want: true},
+		{name: "const float", schema: "five", value: 5.0, want: true},
+		{name: "const numeric string", schema: "five", value: "5", want: false},
+		{name: "enum number", schema: "small", value: 1, want: true},
+		{name: "enum numeric string", schema: "small", value: "1", want: false},
+		{name: "enum bool", schema: "small", value: true, want: false},
+		{name: "enum array", schema: "small", value: []any{3}, want: true},
+		{name: "enum array of string", schema: "small", value: []any{"3"}, want: false},
+		{name: "enum object", schema: "small", value: map[string]any{"n": 4.0}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Top-level strings hold JSON, so values are tested as properties.
+			rule := Rule{Conditions: []Condition{{Field: "doc", Op: OperatorMatchesSchema, Value: tt.schema}}}
+			res, err := e.Evaluate(rule, map[string]any{"doc": map[string]any{"v": tt.value}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "invalid JSON", schema: `{"type":`},
+		{name: "invalid type", schema: `{"type": 5}`},
+		{name: "invalid pattern", schema: `{"pattern": "("}`},
+		{name: "invalid nested schema", schema: `{"properties": {"a": {"pattern": "["}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().RegisterSchema("s", []byte(tt.schema)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
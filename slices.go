@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+)
+
+// OperatorSortedBy matches a slice of objects ordered by a sub-field, e.g.
+// events ascending by timestamp: {"field":"events","op":"sorted_by","value":"ts"}.
+// The value is the sub-field path, or {"by":path,"order":"desc","strict":true}
+// to require descending order or no equal neighbours. Sub-fields are
+// ordered as by gt and lt: numbers numerically, dates chronologically and
+// other types with a registered comparer; elements that are not objects, lack
+// the sub-field or cannot be compared are an error. Slices of fewer than
+// two elements are sorted.
+const OperatorSortedBy Operator = "sorted_by"
+
+func (e *Engine) sortedBy(a, b any) (bool, error) {
+	items, ok := a.([]any)
+	if !ok {
+		return false, typeMismatch("sorted_by", a)
+	}
+	by, desc, strict, err := sortedByParams(b)
+	if err != nil {
+		return false, err
+	}
+	var prev any
+	for i, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return false, fmt.Errorf("sorted_by element %d is not an object", i)
+		}
+		v, ok := getValue(obj, by)
+		if !ok {
+			return false, fmt.Errorf("sorted_by element %d has no field %q", i, by)
+		}
+		if i > 0 {
+			c, ok, err := e.compareCustom(prev, v)
+			if !ok {
+				c, err = compare(prev, v, "sorted_by")
+			}
+			if err != nil {
+				return false, fmt.Errorf("sorted_by element %d: %w", i, err)
+			}
+			if desc {
+				c = -c
+			}
+			if c > 0 || strict && c == 0 {
+				return false, nil
+			}
+		}
+		prev = v
+	}
+	return true, nil
+}
+
+// OperatorNonDecreasing, OperatorNonIncreasing, OperatorIncreasing and
+// OperatorDecreasing match a slice of numbers in monotonic order. The
+// non-strict variants allow plateaus, so [1, 1, 2] is non-decreasing but not
+// increasing. Slices of fewer than two elements match all four, and the
+// condition value is ignored.
+const (
+	OperatorNonDecreasing Operator = "non_decreasing"
+	OperatorNonIncreasing Operator = "non_increasing"
+	OperatorIncreasing    Operator = "increasing"
+	OperatorDecreasing    Operator = "decreasing"
+)
+
+// monotonic returns an operator matching slices whose consecutive numbers
+// all satisfy test, given the ordering of each number against the next.
+func monotonic(op Operator, test func(c int) bool) func(a, b any) (bool, error) {
+	return func(a, _ any) (bool, error) {
+		items, ok := a.([]any)
+		if !ok {
+			return false, typeMismatch(string(op), a)
+		}
+		nums := make([]float64, len(items))
+		for i, item := range items {
+			if nums[i], ok = toFloat(item); !ok {
+				return false, fmt.Errorf("%s element %d is not a number: %v", op, i, item)
+			}
+		}
+		for i := 1; i < len(nums); i++ {
+			if !test(cmp.Compare(nums[i-1], nums[i])) {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// sortedByParams reads the value of OperatorSortedBy.
+func sortedByParams(b any) (by string, desc, strict bool, err error) {
+	if s, ok := b.(string); ok && s != "" {
+		return s, false, false, nil
+	}
+	spec, ok := b.(map[string]any)
+	if !ok {
+		return "", false, false, fmt.Errorf(`sorted_by requires a sub-field or {"by":...} value`)
+	}
+	by, _ = spec["by"].(string)
+	if by == "" {
+		return "", false, false, fmt.Errorf("sorted_by requires a sub-field in by")
+	}
+	switch spec["order"] {
+	case nil, "asc":
+	case "desc":
+		desc = true
+	default:
+		return "", false, false, fmt.Errorf(`sorted_by order must be "asc" or "desc", got %v`, spec["order"])
+	}
+	strict, _ = spec["strict"].(bool)
+	return by, desc, strict, nil
+}
+
+// OperatorIntersectAtLeast matches when a slice field shares at least "min"
+// distinct elements with "set", given as {"set":[...],"min":N}, with
+// elements compared using the engine's eq.
+const OperatorIntersectAtLeast Operator = "intersect_at_least"
+
+func (e *Engine) intersectAtLeast(ctx context.Context, a, b any, data map[string]any) (bool, error) {
+	items, ok := a.([]any)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for intersect_at_least")
+	}
+	spec, _ := b.(map[string]any)
+	set, okSet := spec["set"].([]any)
+	threshold, okMin := toFloat(spec["min"])
+	if !okSet || !okMin {
+		return false, fmt.Errorf(`intersect_at_least requires {"set":[...],"min":N} value`)
+	}
+	eq := e.elementsEqual(ctx, data)
+	n := 0
+	for i, s := range set {
+		seen, err := indexOf(set[:i], s, eq)
+		if err != nil {
+			return false, err
+		}
+		if seen >= 0 {
+			continue
+		}
+		found, err := indexOf(items, s, eq)
+		if err != nil {
+			return false, err
+		}
+		if found >= 0 {
+			n++
+		}
+	}
+	return float64(n) >= threshold, nil
+}
+
+// OperatorPermutationOf matches when a slice field holds exactly the
+// elements of the value slice in any order, respecting multiplicity, with
+// elements compared using the engine's eq, so CaseInsensitive, StrictTypes
+// and eq tolerances apply.
+const OperatorPermutationOf Operator = "permutation_of"
+
+func (e *Engine) permutationOf(ctx context.Context, a, b any, data map[string]any) (bool, error) {
+	items, ok := a.([]any)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for permutation_of")
+	}
+	set, ok := b.([]any)
+	if !ok {
+		return false, fmt.Errorf("permutation_of requires slice value")
+	}
+	if len(items) != len(set) {
+		return false, nil
+	}
+	diff, err := difference(items, set, e.elementsEqual(ctx, data))
+	return len(diff) == 0, err
+}
+
+// elementsEqual is equalsFunc for contextual operators, which are not given
+// the evaluation's state.
+func (e *Engine) elementsEqual(ctx context.Context, data map[string]any) func(x, y any) (bool, error) {
+	return e.equalsFunc(ctx, &evalState{}, data)
+}
+
+// indexOf returns the index of the first element of items equal to v under
+// eq, or -1.
+func indexOf(items []any, v any, eq func(x, y any) (bool, error)) (int, error) {
+	for i, item := range items {
+		same, err := eq(item, v)
+		if err != nil {
+			return -1, err
+		}
+		if same {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,203 @@
+package rules
+
+import "testing"
+
+func TestIntersectAtLeast(t *testing.T) {
+	value := map[string]any{"set": []any{"a", "b", "c", "d"}, "min": 2}
+	tests := []struct {
+		name    string
+		tags    any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "no overlap", tags: []any{"x", "y"}, value: value, want: false},
+		{name: "below threshold", tags: []any{"a", "x"}, value: value, want: false},
+		{name: "at threshold", tags: []any{"b", "x", "d"}, value: value, want: true},
+		{name: "above threshold", tags: []any{"a", "b", "c"}, value: value, want: true},
+		{name: "duplicates count once", tags: []any{"a", "a", "a"}, value: value, want: false},
+		{name: "numeric coercion", tags: []any{1, 2.0}, value: map[string]any{"set": []any{1.0, 2, 3}, "min": 2}, want: true},
+		{name: "not a slice", tags: "a,b", value: value, wantErr: true},
+		{name: "malformed value", tags: []any{"a"}, value: []any{"a"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "tags", Op: OperatorIntersectAtLeast, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"tags": tt.tags})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntersectAtLeastOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		tags  []any
+		value map[string]any
+		want  bool
+	}{
+		{name: "case sensitive", tags: []any{"a", "B"}, value: map[string]any{"set": []any{"A", "b"}, "min": 2}, want: false},
+		{name: "case insensitive", opts: Options{CaseInsensitive: true}, tags: []any{"a", "B"}, value: map[string]any{"set": []any{"A", "b"}, "min": 2}, want: true},
+		{name: "case insensitive duplicates count once", opts: Options{CaseInsensitive: true}, tags: []any{"a"}, value: map[string]any{"set": []any{"A", "a"}, "min": 2}, want: false},
+		{name: "strict types", opts: Options{StrictTypes: true}, tags: []any{"1", "2"}, value: map[string]any{"set": []any{1, 2}, "min": 1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "tags", Op: OperatorIntersectAtLeast, Value: tt.value}}}
+			res, err := NewWithOptions(tt.opts).Evaluate(rule, map[string]any{"tags": tt.tags})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermutationOf(t *testing.T) {
+	value := []any{"build", "test", "deploy"}
+	tests := []struct {
+		name    string
+		steps   any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "same order", steps: []any{"build", "test", "deploy"}, value: value, want: true},
+		{name: "reordered", steps: []any{"deploy", "build", "test"}, value: value, want: true},
+		{name: "missing element", steps: []any{"build", "test"}, value: value, want: false},
+		{name: "extra element", steps: []any{"build", "test", "deploy", "lint"}, value: value, want: false},
+		{name: "duplicate instead of element", steps: []any{"build", "build", "deploy"}, value: value, want: false},
+		{name: "multiplicity respected", steps: []any{"a", "b", "a"}, value: []any{"a", "a", "b"}, want: true},
+		{name: "numeric coercion", steps: []any{2, 1.0}, value: []any{1, 2.0}, want: true},
+		{name: "both empty", steps: []any{}, value: []any{}, want: true},
+		{name: "not a slice", steps: "build", value: value, wantErr: true},
+		{name: "malformed value", steps: []any{"build"}, value: "build", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "steps", Op: OperatorPermutationOf, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"steps": tt.steps})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermutationOfOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		steps []any
+		value []any
+		want  bool
+	}{
+		{name: "case sensitive", steps: []any{"a", "B"}, value: []any{"A", "b"}, want: false},
+		{name: "case insensitive", opts: Options{CaseInsensitive: true}, steps: []any{"a", "B"}, value: []any{"A", "b"}, want: true},
+		{name: "strict types", opts: Options{StrictTypes: true}, steps: []any{"1"}, value: []any{1}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "steps", Op: OperatorPermutationOf, Value: tt.value}}}
+			res, err := NewWithOptions(tt.opts).Evaluate(rule, map[string]any{"steps": tt.steps})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedBy(t *testing.T) {
+	ev := func(ts ...any) []any {
+		out := make([]any, len(ts))
+		for i, v := range ts {
+			out[i] = map[string]any{"ts": v, "meta": map[string]any{"seq": i}}
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		events  any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "sorted numbers", events: ev(1, 2, 2, 5), value: "ts", want: true},
+		{name: "unsorted numbers", events: ev(1, 3, 2), value: "ts", want: false},
+		{name: "sorted dates across zones", events: ev("2024-01-01T10:00:00+02:00", "2024-01-01T09:00:00Z", "2024-01-02"), value: "ts", want: true},
+		{name: "unsorted dates", events: ev("2024-03-01T00:00:00Z", "2024-02-01T00:00:00Z"), value: "ts", want: false},
+		{name: "strict rejects ties", events: ev(1, 2, 2), value: map[string]any{"by": "ts", "strict": true}, want: false},
+		{name: "descending", events: ev(9, 4, 4, 1), value: map[string]any{"by": "ts", "order": "desc"}, want: true},
+		{name: "descending unsorted", events: ev(1, 4), value: map[string]any{"by": "ts", "order": "desc"}, want: false},
+		{name: "nested sub-field", events: ev(5, 1, 3), value: "meta.seq", want: true},
+		{name: "empty", events: []any{}, value: "ts", want: true},
+		{name: "single", events: ev("anything"), value: "ts", want: true},
+		{name: "mixed types", events: ev(1, "2024-01-01T00:00:00Z"), value: "ts", wantErr: true},
+		{name: "strings not ordered", events: ev("b", "a"), value: "ts", wantErr: true},
+		{name: "missing sub-field", events: []any{map[string]any{"ts": 1}, map[string]any{}}, value: "ts", wantErr: true},
+		{name: "non-object element", events: []any{map[string]any{"ts": 1}, 2}, value: "ts", wantErr: true},
+		{name: "not a slice", events: "x", value: "ts", wantErr: true},
+		{name: "bad order", events: ev(1), value: map[string]any{"by": "ts", "order": "up"}, wantErr: true},
+		{name: "missing by", events: ev(1), value: map[string]any{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "events", Op: OperatorSortedBy, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"events": tt.events})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	ops := []Operator{OperatorNonDecreasing, OperatorIncreasing, OperatorNonIncreasing, OperatorDecreasing}
+	tests := []struct {
+		name    string
+		values  any
+		want    [4]bool // in the order of ops
+		wantErr bool
+	}{
+		{name: "plateau", values: []any{1, 1, 2}, want: [4]bool{true, false, false, false}},
+		{name: "strictly increasing", values: []any{1, 2, 3}, want: [4]bool{true, true, false, false}},
+		{name: "descending plateau", values: []any{3, 3.0, 1}, want: [4]bool{false, false, true, false}},
+		{name: "strictly decreasing", values: []any{3, 2.5, -1}, want: [4]bool{false, false, true, true}},
+		{name: "constant", values: []any{2, 2}, want: [4]bool{true, false, true, false}},
+		{name: "unordered", values: []any{1, 3, 2}, want: [4]bool{false, false, false, false}},
+		{name: "single", values: []any{7}, want: [4]bool{true, true, true, true}},
+		{name: "empty", values: []any{}, want: [4]bool{true, true, true, true}},
+		{name: "non-numeric element", values: []any{1, "two"}, wantErr: true},
+		{name: "not a slice", values: 5, wantErr: true},
+	}
+	for _, tt := range tests {
+		for i, op := range ops {
+			t.Run(tt.name+"/"+string(op), func(t *testing.T) {
+				res, err := Evaluate(Rule{Conditions: []Condition{{Field: "v", Op: op}}}, map[string]any{"v": tt.values})
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if res.Matched != tt.want[i] {
+					t.Errorf("Matched = %v, want %v", res.Matched, tt.want[i])
+				}
+			})
+		}
+	}
+}
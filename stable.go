@@ -0,0 +1,41 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// OperatorStable resolves a field several times and matches when every
+// resolution yields a deeply equal value, detecting volatile or
+// non-idempotent resolvers such as virtual fields (see RegisterVirtual). The
+// condition value is the number of resolutions, at least 2; nil means 2.
+// Plain data always resolves the same way and so always matches.
+const OperatorStable Operator = "stable"
+
+func (e *Engine) stable(_ context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	n := 2
+	if c.Value != nil {
+		f, ok := toFloat(c.Value)
+		if !ok || f < 2 || f != float64(int(f)) {
+			return false, fmt.Errorf("stable requires a whole number of resolutions of at least 2")
+		}
+		n = int(f)
+	}
+	first, err := e.field(data, c.Field)
+	if err != nil {
+		return false, err
+	}
+	st.input(c.Field, first)
+	st.resolved(first, c.Value)
+	for range n - 1 {
+		v, err := e.field(data, c.Field)
+		if err != nil {
+			return false, err
+		}
+		if !reflect.DeepEqual(first, v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,49 @@
+package rules
+
+import "testing"
+
+func TestStable(t *testing.T) {
+	e := New()
+	calls := 0
+	e.RegisterVirtual("flapping", func(map[string]any) (any, error) {
+		calls++
+		return calls%2 == 0, nil
+	})
+	e.RegisterVirtual("steady", func(data map[string]any) (any, error) {
+		return map[string]any{"id": data["id"]}, nil
+	})
+	counter := 0
+	e.RegisterVirtual("drifting", func(map[string]any) (any, error) {
+		counter++
+		if counter > 2 {
+			return "changed", nil
+		}
+		return "same", nil
+	})
+	tests := []struct {
+		name    string
+		field   string
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "plain data", field: "id", want: true},
+		{name: "idempotent resolver", field: "steady", want: true},
+		{name: "flapping resolver", field: "flapping", want: false},
+		{name: "drift caught with more resolutions", field: "drifting", value: 3, want: false},
+		{name: "too few resolutions", field: "id", value: 1, wantErr: true},
+		{name: "missing field", field: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: tt.field, Op: OperatorStable, Value: tt.value}}}
+			res, err := e.Evaluate(rule, map[string]any{"id": 7})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// OperatorSameFormat matches when the field has the same format as the field
+// named by the condition value, e.g. shipping_zip sameformat "billing_zip".
+// See format for how the format of a value is derived.
+const OperatorSameFormat Operator = "sameformat"
+
+func (e *Engine) sameFormat(_ context.Context, a, b any, data map[string]any) (bool, error) {
+	ref, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("sameformat requires a field name value")
+	}
+	other, err := e.field(data, ref)
+	if err != nil {
+		return false, err
+	}
+	sa, oka := a.(string)
+	sb, okb := other.(string)
+	if !oka || !okb {
+		return false, fmt.Errorf("type mismatch for sameformat")
+	}
+	return format(sa) == format(sb), nil
+}
+
+// format returns the sequence of character classes in s: upper-case letters
+// become 'A', lower-case letters 'a', digits '9' and whitespace ' '. Any other
+// rune is kept as-is, so separators must line up exactly.
+func format(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			b.WriteByte('A')
+		case unicode.IsLetter(r):
+			b.WriteByte('a')
+		case unicode.IsDigit(r):
+			b.WriteByte('9')
+		case unicode.IsSpace(r):
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// OperatorGlobList matches a string field against an ordered list of
+// shell-style patterns (see path.Match). As with .gitignore, a pattern
+// prefixed with "!" excludes and the last matching pattern wins, so
+// ["*.go", "!*_test.go"] matches main.go but not main_test.go.
+const OperatorGlobList Operator = "globlist"
+
+func globList(a, b any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for globlist")
+	}
+	patterns, ok := b.([]any)
+	if !ok {
+		return false, fmt.Errorf("globlist requires slice value")
+	}
+	matched := false
+	for _, p := range patterns {
+		pattern, ok := p.(string)
+		if !ok {
+			return false, fmt.Errorf("globlist pattern %v is not a string", p)
+		}
+		include := true
+		if rest, found := strings.CutPrefix(pattern, "!"); found {
+			include, pattern = false, rest
+		}
+		hit, err := path.Match(pattern, s)
+		if err != nil {
+			return false, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+		if hit {
+			matched = include
+		}
+	}
+	return matched, nil
+}
+
+// OperatorEntropy matches a string field whose Shannon entropy, in bits,
+// is at least the numeric condition value. The entropy of a string is its
+// per-character entropy, estimated from its own character frequencies,
+// times its length in runes: "aaaa" scores 0 and "abcd" scores 8.
+const OperatorEntropy Operator = "entropy_gte"
+
+func entropyGTE(a, b any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for entropy_gte")
+	}
+	want, ok := toFloat(b)
+	if !ok {
+		return false, fmt.Errorf("entropy_gte requires numeric value")
+	}
+	return entropy(s) >= want, nil
+}
+
+// entropy returns the Shannon entropy of s in bits.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	bits := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		bits -= p * math.Log2(p)
+	}
+	return bits * float64(n)
+}
+
+// OperatorStartsWith and OperatorEndsWith match a string field beginning or
+// ending with the string condition value. Comparison is byte-wise on the
+// UTF-8 encoding, which for valid UTF-8 means whole characters.
+const (
+	OperatorStartsWith Operator = "startswith"
+	OperatorEndsWith   Operator = "endswith"
+)
+
+func startsWith(a, b any) (bool, error) {
+	s, prefix, ok := stringOperands(a, b)
+	if !ok {
+		return false, typeMismatch("startswith", a, b)
+	}
+	return strings.HasPrefix(s, prefix), nil
+}
+
+func endsWith(a, b any) (bool, error) {
+	s, suffix, ok := stringOperands(a, b)
+	if !ok {
+		return false, typeMismatch("endswith", a, b)
+	}
+	return strings.HasSuffix(s, suffix), nil
+}
+
+// stringOperands returns a and b as strings, reporting whether both are.
+func stringOperands(a, b any) (string, string, bool) {
+	sa, oka := a.(string)
+	sb, okb := b.(string)
+	return sa, sb, oka && okb
+}
+
+// OperatorCase matches a string field following the naming convention given
+// as the condition value. Conventions use ASCII letters and digits, start
+// with a letter and have no empty words:
+//
+//	snake   user_id, http2_port   lower-case words joined by "_"
+//	kebab   user-id, http2-port   lower-case words joined by "-"
+//	camel   userId, userID        lower-case first word, later words capitalised
+//	pascal  UserId, HTTPServer    every word capitalised
+//
+// Single lower-case words such as "user" are snake, kebab and camel case.
+const OperatorCase Operator = "is_case"
+
+// casePatterns holds the pattern for each OperatorCase convention.
+var casePatterns = map[string]*regexp.Regexp{
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+	"camel":  regexp.MustCompile(`^[a-z][a-z0-9]*([A-Z][a-z0-9]*)*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-z0-9]*([A-Z][a-z0-9]*)*$`),
+}
+
+func isCase(a, b any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for is_case")
+	}
+	name, ok := b.(string)
+	if !ok {
+		return false, fmt.Errorf("is_case requires a convention name value")
+	}
+	re, ok := casePatterns[name]
+	if !ok {
+		return false, fmt.Errorf("unknown case convention %q: want snake, kebab, camel or pascal", name)
+	}
+	return re.MatchString(s), nil
+}
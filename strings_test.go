@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSameFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		shipping any
+		billing  any
+		want     bool
+		wantErr  bool
+	}{
+		{name: "same digits", shipping: "90210", billing: "10001", want: true},
+		{name: "zip+4 vs zip", shipping: "90210-1234", billing: "10001", want: false},
+		{name: "uk postcodes", shipping: "SW1A 1AA", billing: "EC1A 1BB", want: true},
+		{name: "case differs", shipping: "sw1a 1aa", billing: "EC1A 1BB", want: false},
+		{name: "separator differs", shipping: "90210-1234", billing: "90210 1234", want: false},
+		{name: "non-string", shipping: 90210, billing: "10001", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "shipping_zip", Op: OperatorSameFormat, Value: "billing_zip"}}}
+			res, err := Evaluate(rule, map[string]any{"shipping_zip": tt.shipping, "billing_zip": tt.billing})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameFormatMissingField(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "shipping_zip", Op: OperatorSameFormat, Value: "billing_zip"}}}
+	if _, err := Evaluate(rule, map[string]any{"shipping_zip": "90210"}); err == nil {
+		t.Error("expected error for missing reference field")
+	}
+}
+
+func TestGlobList(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     any
+		patterns any
+		want     bool
+		wantErr  bool
+	}{
+		{name: "include only", file: "main.go", patterns: []any{"*.go"}, want: true},
+		{name: "no pattern matches", file: "README.md", patterns: []any{"*.go"}, want: false},
+		{name: "include then exclude", file: "main_test.go", patterns: []any{"*.go", "!*_test.go"}, want: false},
+		{name: "include then exclude other", file: "main.go", patterns: []any{"*.go", "!*_test.go"}, want: true},
+		{name: "exclude then include", file: "main_test.go", patterns: []any{"!*_test.go", "*.go"}, want: true},
+		{name: "reinclude after exclude", file: "vendor/keep.go", patterns: []any{"vendor/*", "!vendor/*.go", "vendor/keep.go"}, want: true},
+		{name: "bad pattern", file: "a", patterns: []any{"["}, wantErr: true},
+		{name: "non-string pattern", file: "a", patterns: []any{1}, wantErr: true},
+		{name: "not a slice", file: "a", patterns: "*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "file", Op: OperatorGlobList, Value: tt.patterns}}}
+			res, err := Evaluate(rule, map[string]any{"file": tt.file})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password any
+		want     bool
+		wantErr  bool
+	}{
+		{name: "empty", password: "", want: false},
+		{name: "repeated character", password: "aaaaaaaaaaaaaaaa", want: false},
+		{name: "short word", password: "password", want: false},
+		{name: "long mixed", password: "c0rrect-H0rse_Battery!Staple", want: true},
+		{name: "random", password: "t9#Lq2$wZm!8Rv", want: true},
+		{name: "not a string", password: 12345, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "password", Op: OperatorEntropy, Value: 40}}}
+			res, err := Evaluate(rule, map[string]any{"password": tt.password})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v (entropy %.1f)", res.Matched, tt.want, entropy(fmt.Sprint(tt.password)))
+			}
+		})
+	}
+	if got := entropy("abcd"); got != 8 {
+		t.Errorf("entropy(abcd) = %v, want 8", got)
+	}
+}
+
+func TestStartsEndsWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      Operator
+		field   any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "ascii prefix", op: OperatorStartsWith, field: "SKU-1234", value: "SKU-", want: true},
+		{name: "ascii prefix mismatch", op: OperatorStartsWith, field: "ABC-1234", value: "SKU-", want: false},
+		{name: "ascii suffix", op: OperatorEndsWith, field: "report.pdf", value: ".pdf", want: true},
+		{name: "ascii suffix mismatch", op: OperatorEndsWith, field: "report.pdf.exe", value: ".pdf", want: false},
+		{name: "case sensitive", op: OperatorEndsWith, field: "REPORT.PDF", value: ".pdf", want: false},
+		{name: "multi-byte prefix", op: OperatorStartsWith, field: "日本語テキスト", value: "日本", want: true},
+		{name: "multi-byte suffix", op: OperatorEndsWith, field: "naïve café", value: "café", want: true},
+		{name: "emoji suffix", op: OperatorEndsWith, field: "done ✅", value: "✅", want: true},
+		{name: "byte-wise: partial character prefix", op: OperatorStartsWith, field: "\u00e9", value: "\xc3", want: true},
+		{name: "combining accent differs from precomposed", op: OperatorEndsWith, field: "cafe\u0301", value: "caf\u00e9", want: false},
+		{name: "empty affix", op: OperatorStartsWith, field: "x", value: "", want: true},
+		{name: "non-string field", op: OperatorStartsWith, field: 1234, value: "12", wantErr: true},
+		{name: "non-string value", op: OperatorEndsWith, field: "1234", value: 34, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: tt.op, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCase(t *testing.T) {
+	tests := []struct {
+		convention string
+		s          any
+		want       bool
+		wantErr    bool
+	}{
+		{convention: "snake", s: "user_id", want: true},
+		{convention: "snake", s: "http2_port", want: true},
+		{convention: "snake", s: "user", want: true},
+		{convention: "snake", s: "User_id", want: false},
+		{convention: "snake", s: "user__id", want: false},
+		{convention: "snake", s: "_user", want: false},
+		{convention: "snake", s: "user_", want: false},
+		{convention: "snake", s: "user-id", want: false},
+		{convention: "snake", s: "2fa_code", want: false},
+		{convention: "kebab", s: "user-id", want: true},
+		{convention: "kebab", s: "http2-port", want: true},
+		{convention: "kebab", s: "user_id", want: false},
+		{convention: "kebab", s: "user--id", want: false},
+		{convention: "kebab", s: "User-Id", want: false},
+		{convention: "camel", s: "userId", want: true},
+		{convention: "camel", s: "userID", want: true},
+		{convention: "camel", s: "user", want: true},
+		{convention: "camel", s: "UserId", want: false},
+		{convention: "camel", s: "user_id", want: false},
+		{convention: "pascal", s: "UserId", want: true},
+		{convention: "pascal", s: "HTTPServer", want: true},
+		{convention: "pascal", s: "User", want: true},
+		{convention: "pascal", s: "userId", want: false},
+		{convention: "pascal", s: "User_Id", want: false},
+		{convention: "pascal", s: "", want: false},
+		{convention: "snake", s: "na\u00efve_name", want: false},
+		{convention: "train", s: "User-Id", wantErr: true},
+		{convention: "snake", s: 42, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v", tt.convention, tt.s), func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "name", Op: OperatorCase, Value: tt.convention}}}
+			res, err := Evaluate(rule, map[string]any{"name": tt.s})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// OperatorJSONMatch matches a string field holding a JSON object against a
+// nested Rule given as the condition value. Strings that are not a JSON
+// object do not match.
+const OperatorJSONMatch Operator = "jsonmatch"
+
+func (e *Engine) jsonMatch(ctx context.Context, a, b any, _ map[string]any) (bool, error) {
+	s, ok := a.(string)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for jsonmatch")
+	}
+	rule, err := toRule(b)
+	if err != nil {
+		return false, fmt.Errorf("jsonmatch: %w", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(s), &doc); err != nil || doc == nil {
+		return false, nil
+	}
+	res, err := e.evaluate(ctx, rule, doc)
+	if err != nil {
+		return false, fmt.Errorf("jsonmatch: %w", err)
+	}
+	return res.Matched, nil
+}
+
+// OperatorAll and OperatorAny apply a nested Rule, given as the condition
+// value, to every element of a slice of objects. Field paths in the nested
+// rule are relative to each element. OperatorAll matches when every element
+// satisfies the rule and so matches an empty slice; OperatorAny matches when
+// at least one element does and so never matches an empty slice.
+//
+// Slices may mix objects with other values, as JSON arrays often do. Elements
+// that are not objects cannot satisfy the rule: OperatorAny skips them, and
+// OperatorAll therefore does not match a slice containing one.
+//
+// The value may instead be a single nested Condition, written as an object
+// with an "op", to test elements of any type: an empty Field refers to the
+// element itself, e.g. {"op":"gt","value":10} for "any quantity > 10", and
+// any other Field is a path within an object element, e.g.
+// {"field":"quantity","op":"gt","value":10}. An element missing the field,
+// such as an object without it or a non-object element, does not satisfy the
+// condition; any other error, such as a string compared with gt, is returned.
+const (
+	OperatorAll Operator = "all"
+	OperatorAny Operator = "any"
+)
+
+// quantify implements OperatorAll and OperatorAny.
+func (e *Engine) quantify(ctx context.Context, st *evalState, cond Condition, data map[string]any) (bool, error) {
+	a, b, err := e.operands(ctx, st, cond, data)
+	if err != nil {
+		return false, err
+	}
+	op := cond.Op
+	items, ok := a.([]any)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for %s", op)
+	}
+	if c, ok, err := toCondition(b); ok {
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		return e.quantifyCondition(ctx, st, op, items, c)
+	}
+	rule, err := toRule(b)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	for i, item := range items {
+		elem, ok := item.(map[string]any)
+		if !ok {
+			if op == OperatorAll {
+				return false, nil
+			}
+			continue
+		}
+		res, err := e.evaluate(ctx, rule, elem)
+		if err != nil {
+			return false, fmt.Errorf("%s: element %d: %w", op, i, err)
+		}
+		if op == OperatorAny && res.Matched {
+			return true, nil
+		}
+		if op == OperatorAll && !res.Matched {
+			return false, nil
+		}
+	}
+	return op == OperatorAll, nil
+}
+
+// quantifyCondition applies c to each element of items for quantify.
+func (e *Engine) quantifyCondition(ctx context.Context, st *evalState, op Operator, items []any, c Condition) (bool, error) {
+	if c.Op != "" && !e.hasOperator(c.Op) {
+		return false, fmt.Errorf("%s: %w %q", op, ErrUnknownOperator, c.Op)
+	}
+	// The memo is keyed by operands alone, which is sound only within one
+	// data map, so each element gets its own.
+	outer := st.memo
+	st.nested++
+	defer func() { st.memo = outer; st.nested-- }()
+	for i, item := range items {
+		st.memo = nil
+		// The element is stored under the empty key, which an empty Field
+		// path resolves to.
+		data := map[string]any{"": item}
+		if elem, ok := item.(map[string]any); ok && c.Field != "" {
+			data = elem
+		}
+		matched, _, err := e.evalCondition(ctx, st, c, data)
+		var missing *missingFieldError
+		if errors.As(err, &missing) {
+			matched, err = false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: element %d: %w", op, i, err)
+		}
+		if op == OperatorAny && matched {
+			return true, nil
+		}
+		if op == OperatorAll && !matched {
+			return false, nil
+		}
+	}
+	return op == OperatorAll, nil
+}
+
+// toCondition converts a condition value holding a single Condition, as used
+// by the quantifiers. ok is false for values that are not a condition; maps
+// are conditions when they have an "op" key.
+func toCondition(v any) (c Condition, ok bool, err error) {
+	switch x := v.(type) {
+	case Condition:
+		return x, true, nil
+	case *Condition:
+		if x != nil {
+			return *x, true, nil
+		}
+	case map[string]any:
+		if _, hasOp := x["op"]; !hasOp {
+			return Condition{}, false, nil
+		}
+		b, err := json.Marshal(x)
+		if err != nil {
+			return Condition{}, true, err
+		}
+		if err := json.Unmarshal(b, &c); err != nil {
+			return Condition{}, true, err
+		}
+		return c, true, nil
+	}
+	return Condition{}, false, nil
+}
+
+// toRule converts a condition value into a Rule. Values decoded from JSON
+// arrive as map[string]any and are converted with a JSON round-trip.
+func toRule(v any) (Rule, error) {
+	switch r := v.(type) {
+	case Rule:
+		return r, nil
+	case *Rule:
+		if r != nil {
+			return *r, nil
+		}
+	case map[string]any:
+		b, err := json.Marshal(r)
+		if err != nil {
+			return Rule{}, err
+		}
+		var rule Rule
+		if err := json.Unmarshal(b, &rule); err != nil {
+			return Rule{}, err
+		}
+		return rule, nil
+	}
+	return Rule{}, fmt.Errorf("value must be a rule, got %T", v)
+}
@@ -0,0 +1,192 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONMatch(t *testing.T) {
+	sub := Rule{Conditions: []Condition{
+		{Field: "user.role", Op: OperatorEQ, Value: "admin"},
+		{Field: "amount", Op: OperatorGT, Value: 10},
+	}}
+	tests := []struct {
+		name    string
+		payload any
+		want    bool
+		wantErr bool
+	}{
+		{name: "matching payload", payload: `{"user":{"role":"admin"},"amount":50}`, want: true},
+		{name: "non-matching payload", payload: `{"user":{"role":"user"},"amount":50}`, want: false},
+		{name: "invalid json", payload: `{"user":`, want: false},
+		{name: "json array", payload: `[1,2,3]`, want: false},
+		{name: "json null", payload: `null`, want: false},
+		{name: "not a string", payload: 42, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "payload", Op: OperatorJSONMatch, Value: sub}}}
+			res, err := Evaluate(rule, map[string]any{"payload": tt.payload})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONMatchFromJSONRule(t *testing.T) {
+	src := `{"conditions":[{"field":"payload","op":"jsonmatch","value":{"conditions":[{"field":"status","op":"eq","value":"paid"}]}}]}`
+	var rule Rule
+	if err := json.Unmarshal([]byte(src), &rule); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Evaluate(rule, map[string]any{"payload": `{"status":"paid"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected embedded JSON to match sub-rule")
+	}
+}
+
+func TestQuantifiers(t *testing.T) {
+	inStock := Rule{Conditions: []Condition{{Field: "stock", Op: OperatorGT, Value: 0}}}
+	all := []any{
+		map[string]any{"sku": "a", "stock": 3},
+		map[string]any{"sku": "b", "stock": 1},
+	}
+	some := []any{
+		map[string]any{"sku": "a", "stock": 3},
+		map[string]any{"sku": "b", "stock": 0},
+	}
+	none := []any{
+		map[string]any{"sku": "a", "stock": 0},
+	}
+	tests := []struct {
+		name    string
+		op      Operator
+		items   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "all satisfy: all", op: OperatorAll, items: all, want: true},
+		{name: "all satisfy: any", op: OperatorAny, items: all, want: true},
+		{name: "some satisfy: all", op: OperatorAll, items: some, want: false},
+		{name: "some satisfy: any", op: OperatorAny, items: some, want: true},
+		{name: "none satisfy: any", op: OperatorAny, items: none, want: false},
+		{name: "empty: all is vacuously true", op: OperatorAll, items: []any{}, want: true},
+		{name: "empty: any is false", op: OperatorAny, items: []any{}, want: false},
+		{name: "not a slice", op: OperatorAll, items: "a", wantErr: true},
+		{name: "scalar element: all", op: OperatorAll, items: []any{1}, want: false},
+		{name: "scalar element: any", op: OperatorAny, items: []any{1}, want: false},
+		{name: "mixed types: any skips scalars", op: OperatorAny, items: []any{"x", 7, nil, []any{1}, map[string]any{"stock": 2}}, want: true},
+		{name: "mixed types: any without match", op: OperatorAny, items: []any{"x", map[string]any{"stock": 0}, true}, want: false},
+		{name: "mixed types: all", op: OperatorAll, items: []any{map[string]any{"stock": 2}, "x"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "items", Op: tt.op, Value: inStock}}}
+			res, err := Evaluate(rule, map[string]any{"items": tt.items})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantifiersWithCondition(t *testing.T) {
+	orders := []any{
+		map[string]any{"sku": "a", "quantity": 2},
+		map[string]any{"sku": "b", "quantity": 12},
+	}
+	tests := []struct {
+		name    string
+		op      Operator
+		items   any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "field of object elements", op: OperatorAny, items: orders, value: Condition{Field: "quantity", Op: OperatorGT, Value: 10}, want: true},
+		{name: "field of object elements: none", op: OperatorAny, items: orders, value: Condition{Field: "quantity", Op: OperatorGT, Value: 20}, want: false},
+		{name: "field of object elements: all", op: OperatorAll, items: orders, value: Condition{Field: "quantity", Op: OperatorGT, Value: 1}, want: true},
+		{name: "JSON-decoded condition", op: OperatorAny, items: orders, value: map[string]any{"field": "sku", "op": "eq", "value": "b"}, want: true},
+		{name: "scalar elements", op: OperatorAny, items: []any{3, 7, 11}, value: map[string]any{"op": "gt", "value": 10}, want: true},
+		{name: "scalar elements: all", op: OperatorAll, items: []any{3, 7, 11}, value: map[string]any{"op": "gt", "value": 2}, want: true},
+		{name: "negated element condition", op: OperatorAll, items: []any{"a", "b"}, value: Condition{Op: OperatorEQ, Value: "c", Negate: true}, want: true},
+		{name: "empty slice: any", op: OperatorAny, items: []any{}, value: Condition{Op: OperatorGT, Value: 0}, want: false},
+		{name: "empty slice: all", op: OperatorAll, items: []any{}, value: Condition{Op: OperatorGT, Value: 0}, want: true},
+		{name: "mixed types: any", op: OperatorAny, items: []any{"x", nil, 5, map[string]any{"quantity": 15}}, value: Condition{Field: "quantity", Op: OperatorGT, Value: 10}, want: true},
+		{name: "mixed types: any without match", op: OperatorAny, items: []any{"x", true, map[string]any{"quantity": 5}}, value: Condition{Field: "quantity", Op: OperatorGT, Value: 10}, want: false},
+		{name: "mixed types: all", op: OperatorAll, items: []any{map[string]any{"quantity": 15}, "x"}, value: Condition{Field: "quantity", Op: OperatorGT, Value: 10}, want: false},
+		{name: "type mismatch", op: OperatorAny, items: []any{15, "x"}, value: Condition{Op: OperatorGT, Value: 20}, wantErr: true},
+		{name: "null element", op: OperatorAll, items: []any{15, nil}, value: Condition{Op: OperatorGT, Value: 10}, wantErr: true},
+		{name: "object missing field", op: OperatorAny, items: []any{map[string]any{}, 5}, value: Condition{Field: "quantity", Op: OperatorGT, Value: 1}, want: false},
+		{name: "unknown operator", op: OperatorAny, items: []any{1}, value: Condition{Op: "approx", Value: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "items", Op: tt.op, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"items": tt.items})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantifierConditionSharesEvaluation(t *testing.T) {
+	t.Run("resolver error", func(t *testing.T) {
+		e := New()
+		e.Resolver = FieldResolverFunc(func(string) (any, bool, error) { return nil, false, errors.New("backend down") })
+		rule := Rule{Conditions: []Condition{{Field: "items", Op: OperatorAny, Value: Condition{Field: "quantity", Op: OperatorGT, Value: 1}}}}
+		_, err := e.Evaluate(rule, map[string]any{"items": []any{map[string]any{}}})
+		var re *ResolveError
+		if !errors.As(err, &re) {
+			t.Fatalf("error = %v, want *ResolveError", err)
+		}
+	})
+	t.Run("trace", func(t *testing.T) {
+		e := New()
+		e.Trace = true
+		rule := Rule{Conditions: []Condition{{Field: "items", Op: OperatorAll, Value: Condition{Op: OperatorGT, Value: 10}}}}
+		res, err := e.Evaluate(rule, map[string]any{"items": []any{12, 13, 14}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Matched {
+			t.Error("Matched = false, want true")
+		}
+		if len(res.Trace) != 4 {
+			t.Errorf("trace has %d records, want 4: %+v", len(res.Trace), res.Trace)
+		}
+	})
+	t.Run("memo per element", func(t *testing.T) {
+		rule := Rule{Conditions: []Condition{{Field: "items", Op: OperatorAll, Value: Condition{Field: "zip", Op: OperatorSameFormat, Value: "billing"}}}}
+		data := map[string]any{"items": []any{
+			map[string]any{"zip": "12345", "billing": "99999"},
+			map[string]any{"zip": "12345", "billing": "AB1 2CD"},
+		}}
+		for _, memoize := range []bool{false, true} {
+			e := New()
+			e.Memoize = memoize
+			res, err := e.Evaluate(rule, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Matched {
+				t.Errorf("Memoize %v: Matched = true, want false", memoize)
+			}
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package rules
+
+import "fmt"
+
+// Cell is one decision table entry: the operator and value applied to its
+// column's field. A cell with an empty Op matches anything.
+type Cell struct {
+	Op    Operator `json:"op,omitempty"`
+	Value any      `json:"value,omitempty"`
+}
+
+// DecisionRow is a decision table row: one cell per column and the outcome
+// produced when every cell matches.
+type DecisionRow struct {
+	Cells   []Cell `json:"cells"`
+	Outcome string `json:"outcome"`
+}
+
+// DecisionTable expresses a rule as rows of per-column conditions. Columns
+// are field paths; rows are tried in order and the first fully matching row
+// decides the outcome, falling back to Default.
+type DecisionTable struct {
+	Columns []string      `json:"columns"`
+	Rows    []DecisionRow `json:"rows"`
+	Default string        `json:"default,omitempty"`
+}
+
+// EvaluateTable returns the outcome of the first row of dt matching data,
+// or dt.Default when none does.
+func (e *Engine) EvaluateTable(dt DecisionTable, data map[string]any) (string, error) {
+	for i, row := range dt.Rows {
+		if len(row.Cells) != len(dt.Columns) {
+			return "", fmt.Errorf("decision table row %d has %d cells, want %d", i, len(row.Cells), len(dt.Columns))
+		}
+		var rule Rule
+		for j, cell := range row.Cells {
+			if cell.Op == "" {
+				continue
+			}
+			rule.Conditions = append(rule.Conditions, Condition{Field: dt.Columns[j], Op: cell.Op, Value: cell.Value})
+		}
+		res, err := e.Evaluate(rule, data)
+		if err != nil {
+			return "", fmt.Errorf("decision table row %d: %w", i, err)
+		}
+		if res.Matched {
+			return row.Outcome, nil
+		}
+	}
+	return dt.Default, nil
+}
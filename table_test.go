@@ -0,0 +1,41 @@
+package rules
+
+import "testing"
+
+func TestEvaluateTable(t *testing.T) {
+	dt := DecisionTable{
+		Columns: []string{"country", "amount"},
+		Rows: []DecisionRow{
+			{Cells: []Cell{{Op: OperatorEQ, Value: "US"}, {Op: OperatorGT, Value: 1000}}, Outcome: "review"},
+			{Cells: []Cell{{Op: OperatorEQ, Value: "US"}, {}}, Outcome: "approve"},
+			{Cells: []Cell{{}, {Op: OperatorGT, Value: 500}}, Outcome: "review"},
+		},
+		Default: "approve-intl",
+	}
+	tests := []struct {
+		name string
+		data map[string]any
+		want string
+	}{
+		{name: "first row wins over overlapping second", data: map[string]any{"country": "US", "amount": 5000}, want: "review"},
+		{name: "wildcard amount", data: map[string]any{"country": "US", "amount": 10}, want: "approve"},
+		{name: "wildcard country", data: map[string]any{"country": "FR", "amount": 900}, want: "review"},
+		{name: "default", data: map[string]any{"country": "FR", "amount": 100}, want: "approve-intl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().EvaluateTable(dt, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("EvaluateTable = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	bad := DecisionTable{Columns: []string{"a", "b"}, Rows: []DecisionRow{{Cells: []Cell{{}}, Outcome: "x"}}}
+	if _, err := New().EvaluateTable(bad, map[string]any{}); err == nil {
+		t.Error("expected error for row with wrong cell count")
+	}
+}
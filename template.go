@@ -0,0 +1,31 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs overrides text/template builtins that could reach outside
+// the data: "call" would otherwise invoke function values found in the data
+// map, so it always fails.
+var templateFuncs = template.FuncMap{
+	"call": func(...any) (any, error) {
+		return nil, errors.New("call is not allowed in rule templates")
+	},
+}
+
+// executeTemplate renders src as a text/template against data. Missing keys
+// are errors rather than "<no value>".
+func executeTemplate(src string, data map[string]any) (string, error) {
+	tmpl, err := template.New("value").Funcs(templateFuncs).Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("invalid value template %q: %w", src, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("value template %q: %w", src, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,51 @@
+package rules
+
+import "testing"
+
+func TestTemplateValues(t *testing.T) {
+	e := New()
+	e.TemplateValues = true
+	data := map[string]any{
+		"amount": 150,
+		"base":   100,
+		"limits": map[string]any{"daily": 120},
+		"region": "eu",
+		"fn":     func() int { return 1 },
+	}
+	tests := []struct {
+		name    string
+		cond    Condition
+		want    bool
+		wantErr bool
+	}{
+		{name: "numeric threshold", cond: Condition{Field: "amount", Op: OperatorGT, Value: "{{ .base }}"}, want: true},
+		{name: "nested threshold", cond: Condition{Field: "amount", Op: OperatorLTE, Value: "{{ .limits.daily }}"}, want: false},
+		{name: "string template", cond: Condition{Field: "region", Op: OperatorEQ, Value: "{{ .region }}"}, want: true},
+		{name: "plain string untouched", cond: Condition{Field: "region", Op: OperatorEQ, Value: "eu"}, want: true},
+		{name: "missing key", cond: Condition{Field: "amount", Op: OperatorGT, Value: "{{ .nope }}"}, wantErr: true},
+		{name: "call blocked", cond: Condition{Field: "amount", Op: OperatorGT, Value: "{{ call .fn }}"}, wantErr: true},
+		{name: "invalid template", cond: Condition{Field: "amount", Op: OperatorGT, Value: "{{ .base "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(Rule{Conditions: []Condition{tt.cond}}, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateValuesDisabled(t *testing.T) {
+	rule := Rule{Conditions: []Condition{{Field: "note", Op: OperatorEQ, Value: "{{ .base }}"}}}
+	res, err := New().Evaluate(rule, map[string]any{"note": "{{ .base }}", "base": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("templates should be compared literally unless enabled")
+	}
+}
@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"context"
+	"time"
+)
+
+// SpanName is the name of the span recorded for each evaluation.
+const SpanName = "rules.Evaluate"
+
+// Attributes recorded on evaluation spans.
+const (
+	AttrRuleHash     = "rule.hash"        // string, as in DecisionLog.RuleHash
+	AttrRuleMatched  = "rule.matched"     // bool
+	AttrRuleDuration = "rule.duration_ms" // float64
+)
+
+// Tracer starts spans for evaluations. It keeps the engine free of tracing
+// dependencies; NewTracer in the separate github.com/njchilds90/go-rules/otel
+// module adapts an OpenTelemetry tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// SetAttribute records a string, bool or float64 attribute.
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// evaluateTraced evaluates p within a span from e.Tracer.
+func (e *Engine) evaluateTraced(ctx context.Context, p *plan, data map[string]any) (Result, error) {
+	start := time.Now()
+	ctx, span := e.Tracer.Start(ctx, SpanName)
+	defer span.End()
+	if hash, err := ruleHash(p.rule); err == nil {
+		span.SetAttribute(AttrRuleHash, hash)
+	}
+	res, err := e.evaluateHooked(ctx, p, data)
+	span.SetAttribute(AttrRuleMatched, res.Matched)
+	span.SetAttribute(AttrRuleDuration, float64(time.Since(start))/float64(time.Millisecond))
+	if err != nil {
+		span.RecordError(err)
+	}
+	return res, err
+}
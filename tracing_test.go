@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &testSpan{name: name, attrs: map[string]any{}}
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+type testSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)              { s.err = err }
+func (s *testSpan) End()                               { s.ended = true }
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	e := New()
+	e.Tracer = tracer
+	rule := Rule{Conditions: []Condition{{Field: "age", Op: OperatorGTE, Value: 18}}}
+	hash, err := ruleHash(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := e.Evaluate(rule, map[string]any{"age": 30}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	if s.name != SpanName || !s.ended || s.err != nil {
+		t.Errorf("span = %+v, want ended %q span without error", s, SpanName)
+	}
+	if s.attrs[AttrRuleHash] != hash || s.attrs[AttrRuleMatched] != true {
+		t.Errorf("attributes = %v", s.attrs)
+	}
+	if d, ok := s.attrs[AttrRuleDuration].(float64); !ok || d < 0 {
+		t.Errorf("%s = %v, want non-negative float64", AttrRuleDuration, s.attrs[AttrRuleDuration])
+	}
+
+	_, err = e.Evaluate(rule, map[string]any{})
+	if s := tracer.spans[1]; !errors.Is(s.err, ErrFieldNotFound) || !s.ended || s.attrs[AttrRuleMatched] != false {
+		t.Errorf("failed evaluation span = %+v, evaluation error %v", s, err)
+	}
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// RegisterTransform registers a named transformer that Condition.Transform
+// and Condition.Transforms can apply to a field value before the operator
+// runs. Built-ins are "upper", "lower", "trim", "slugify", "abs" and "round".
+func (e *Engine) RegisterTransform(name string, fn func(any) (any, error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.transforms[name] = fn
+}
+
+func (e *Engine) registerDefaultTransforms() {
+	e.transforms["upper"] = stringTransform("upper", strings.ToUpper)
+	e.transforms["lower"] = stringTransform("lower", strings.ToLower)
+	e.transforms["trim"] = stringTransform("trim", strings.TrimSpace)
+	e.transforms["slugify"] = stringTransform("slugify", slugify)
+	e.transforms["abs"] = numberTransform("abs", math.Abs)
+	e.transforms["round"] = numberTransform("round", math.Round)
+}
+
+// transform applies the named transformer to v.
+func (e *Engine) transform(name string, v any) (any, error) {
+	e.mu.RLock()
+	fn, ok := e.transforms[name]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q", name)
+	}
+	return fn(v)
+}
+
+func stringTransform(name string, fn func(string) string) func(any) (any, error) {
+	return func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch for transform %s", name)
+		}
+		return fn(s), nil
+	}
+}
+
+func numberTransform(name string, fn func(float64) float64) func(any) (any, error) {
+	return func(v any) (any, error) {
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("type mismatch for transform %s", name)
+		}
+		return fn(f), nil
+	}
+}
+
+// slugify lowercases s and replaces each run of characters other than
+// letters and digits with a single hyphen, trimming hyphens at either end:
+// "Hello, World!" becomes "hello-world".
+func slugify(s string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if sep && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return b.String()
+}
+
+// Compose returns an operator that applies transform to the field value and
+// then runs the simple operator op, for registering compact composites such
+// as "abs_gt":
+//
+//	e.Register("abs_gt", e.Compose(abs, rules.OperatorGT))
+//
+// op is looked up in e when the composite runs, so it may be registered
+// later or replaced.
+func (e *Engine) Compose(transform func(any) (any, error), op Operator) func(any, any) (bool, error) {
+	return func(a, b any) (bool, error) {
+		e.mu.RLock()
+		fn, ok := e.ops[op]
+		e.mu.RUnlock()
+		if !ok {
+			return false, fmt.Errorf("%w %q", ErrUnknownOperator, op)
+		}
+		v, err := transform(a)
+		if err != nil {
+			return false, err
+		}
+		return fn(v, b)
+	}
+}
+
+// Compose composes a transform with an operator of the default engine; see
+// Engine.Compose.
+func Compose(transform func(any) (any, error), op Operator) func(any, any) (bool, error) {
+	return Default.Compose(transform, op)
+}
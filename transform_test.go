@@ -0,0 +1,142 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		field     any
+		op        Operator
+		value     any
+		want      bool
+		wantErr   bool
+	}{
+		{name: "no transform", field: "us", op: OperatorEQ, value: "US", want: false},
+		{name: "upper", transform: "upper", field: "us", op: OperatorEQ, value: "US", want: true},
+		{name: "lower", transform: "lower", field: "Gold", op: OperatorIn, value: []any{"gold", "silver"}, want: true},
+		{name: "abs", transform: "abs", field: -42, op: OperatorGT, value: 40, want: true},
+		{name: "round", transform: "round", field: 2.6, op: OperatorEQ, value: 3, want: true},
+		{name: "upper on number", transform: "upper", field: 1, op: OperatorEQ, value: 1, wantErr: true},
+		{name: "unknown transform", transform: "reverse", field: "us", op: OperatorEQ, value: "su", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: tt.op, Value: tt.value, Transform: tt.transform}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTransform(t *testing.T) {
+	e := New()
+	e.RegisterTransform("domain", func(v any) (any, error) {
+		s, _ := v.(string)
+		for i := len(s) - 1; i >= 0; i-- {
+			if s[i] == '@' {
+				return s[i+1:], nil
+			}
+		}
+		return s, nil
+	})
+	rule := Rule{Conditions: []Condition{{Field: "email", Op: OperatorEQ, Value: "example.com", Transform: "domain"}}}
+	res, err := e.Evaluate(rule, map[string]any{"email": "ada@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Matched {
+		t.Error("expected custom transform to apply")
+	}
+}
+
+func TestTransformPipeline(t *testing.T) {
+	tests := []struct {
+		name       string
+		transform  string
+		transforms []string
+		field      any
+		value      any
+		want       bool
+		wantErr    bool
+	}{
+		{name: "trim lower slugify", transforms: []string{"trim", "lower", "slugify"}, field: "  Hello, World!  ", value: "hello-world", want: true},
+		{name: "partial pipeline", transforms: []string{"trim", "lower"}, field: "  Hello, World!  ", value: "hello-world", want: false},
+		{name: "order matters", transforms: []string{"slugify", "upper"}, field: "Hello World", value: "hello-world", want: false},
+		{name: "order applied left to right", transforms: []string{"slugify", "upper"}, field: "Hello World", value: "HELLO-WORLD", want: true},
+		{name: "after transform", transform: "trim", transforms: []string{"upper"}, field: " us ", value: "US", want: true},
+		{name: "numeric pipeline", transforms: []string{"abs", "round"}, field: -2.6, value: 3, want: true},
+		{name: "failing step", transforms: []string{"abs", "upper"}, field: -1, value: "1", wantErr: true},
+		{name: "unknown step", transforms: []string{"trim", "reverse"}, field: "x", value: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: OperatorEQ, Value: tt.value, Transform: tt.transform, Transforms: tt.transforms}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Hello, World!":     "hello-world",
+		"  --a__b--  ":      "a-b",
+		"Crème Brûlée 2024": "crème-brûlée-2024",
+		"":                  "",
+	}
+	for in, want := range tests {
+		if got := slugify(in); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	abs := func(v any) (any, error) {
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("abs requires a number")
+		}
+		return math.Abs(f), nil
+	}
+	e := New()
+	e.Register("abs_gt", e.Compose(abs, OperatorGT))
+	tests := []struct {
+		field   any
+		want    bool
+		wantErr bool
+	}{
+		{-5, true, false},
+		{5, true, false},
+		{-2, false, false},
+		{"x", false, true},
+	}
+	for _, tt := range tests {
+		res, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "v", Op: "abs_gt", Value: 3}}}, map[string]any{"v": tt.field})
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%v abs_gt 3: error = %v, wantErr %v", tt.field, err, tt.wantErr)
+		}
+		if res.Matched != tt.want {
+			t.Errorf("%v abs_gt 3 = %v, want %v", tt.field, res.Matched, tt.want)
+		}
+	}
+
+	if _, err := Compose(abs, "nope")(1, 1); err == nil {
+		t.Error("expected error for unknown composed operator")
+	}
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Unit hints for Condition.Unit. When a condition names a unit, both operands
+// are normalised to that unit's base (nanoseconds or bytes) before the
+// operator runs, so "500ms" lt "1s" and "1GB" eq "1024MB" hold. Plain numbers
+// are taken to be in the base unit already.
+const (
+	UnitDuration = "duration"
+	UnitBytes    = "bytes"
+)
+
+// byteUnits maps byte-size suffixes to their multiplier. Decimal-looking
+// suffixes use binary multiples, so 1KB == 1KiB == 1024 bytes.
+var byteUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+	"p": 1 << 50, "pb": 1 << 50, "pib": 1 << 50,
+}
+
+// normalizeUnit converts v to a float64 in the base of unit. Slices are
+// normalised element-wise so list operators keep working.
+func normalizeUnit(unit string, v any) (any, error) {
+	if items, ok := v.([]any); ok {
+		out := make([]any, len(items))
+		for i, item := range items {
+			n, err := normalizeUnit(unit, item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return out, nil
+	}
+	switch unit {
+	case UnitDuration:
+		return parseDurationUnit(v)
+	case UnitBytes:
+		return parseByteUnit(v)
+	}
+	return nil, fmt.Errorf("unknown unit %q", unit)
+}
+
+func parseDurationUnit(v any) (float64, error) {
+	switch x := v.(type) {
+	case time.Duration:
+		return float64(x), nil
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(x))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", x, err)
+		}
+		return float64(d), nil
+	}
+	if f, ok := toFloat(v); ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("invalid duration %v", v)
+}
+
+func parseByteUnit(v any) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		if f, ok := toFloat(v); ok {
+			return f, nil
+		}
+		return 0, fmt.Errorf("invalid byte size %v", v)
+	}
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	mult, ok := byteUnits[strings.ToLower(s[i:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown suffix %q", s, s[i:])
+	}
+	return n * mult, nil
+}
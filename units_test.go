@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnitComparisons(t *testing.T) {
+	tests := []struct {
+		name    string
+		unit    string
+		field   any
+		op      Operator
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{name: "ms lt s", unit: UnitDuration, field: "500ms", op: OperatorLT, value: "1s", want: true},
+		{name: "minutes gt seconds", unit: UnitDuration, field: "2m", op: OperatorGT, value: "90s", want: true},
+		{name: "duration value", unit: UnitDuration, field: 1500 * time.Millisecond, op: OperatorEQ, value: "1.5s", want: true},
+		{name: "GB eq MB", unit: UnitBytes, field: "1GB", op: OperatorEQ, value: "1024MB", want: true},
+		{name: "KiB lt MB mixed case", unit: UnitBytes, field: "512kib", op: OperatorLT, value: "1mb", want: true},
+		{name: "fractional size", unit: UnitBytes, field: "1.5 GB", op: OperatorGT, value: "1536MB", want: false},
+		{name: "plain bytes", unit: UnitBytes, field: 2048, op: OperatorEQ, value: "2K", want: true},
+		{name: "in list", unit: UnitDuration, field: "60s", op: OperatorIn, value: []any{"1m", "2m"}, want: true},
+		{name: "bad duration", unit: UnitDuration, field: "fast", op: OperatorLT, value: "1s", wantErr: true},
+		{name: "bad suffix", unit: UnitBytes, field: "1XB", op: OperatorLT, value: "1GB", wantErr: true},
+		{name: "unknown unit", unit: "furlongs", field: "1", op: OperatorLT, value: "2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "v", Op: tt.op, Value: tt.value, Unit: tt.unit}}}
+			res, err := Evaluate(rule, map[string]any{"v": tt.field})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
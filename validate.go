@@ -0,0 +1,179 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UnmarshalRule decodes a JSON rule and checks that every operator it uses is
+// registered with e, so rules relying on custom operators fail when loaded
+// rather than when evaluated. The rules and conditions nested in all, any
+// and jsonmatch values are checked too. Unknown operators are listed in one
+// error wrapping ErrUnknownOperator. Use Validate for the full set of checks.
+func (e *Engine) UnmarshalRule(data []byte) (Rule, error) {
+	var rule Rule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return Rule{}, err
+	}
+	if unknown := e.unknownOperators(rule, nil); len(unknown) > 0 {
+		slices.Sort(unknown)
+		return Rule{}, fmt.Errorf("%w: %s", ErrUnknownOperator, strings.Join(slices.Compact(unknown), ", "))
+	}
+	return rule, nil
+}
+
+// unknownOperators appends the quoted operators of rule and its nested rules
+// that are not registered with e to dst.
+func (e *Engine) unknownOperators(rule Rule, dst []string) []string {
+	conds := rule.Conditions
+	if rule.Root != nil {
+		conds = rule.Root.conditions(slices.Clone(conds))
+	}
+	for _, c := range conds {
+		dst = e.unknownConditionOperators(c, dst)
+	}
+	return dst
+}
+
+// unknownConditionOperators is unknownOperators for a single condition.
+// Nested values that do not decode are left for evaluation to report.
+func (e *Engine) unknownConditionOperators(c Condition, dst []string) []string {
+	if c.Op == "" {
+		return dst
+	}
+	if !e.hasOperator(c.Op) {
+		return append(dst, strconv.Quote(string(c.Op)))
+	}
+	if c.ValueField != "" || isValueRef(c.Value) {
+		return dst
+	}
+	switch c.Op {
+	case OperatorAll, OperatorAny:
+		if nested, ok, err := toCondition(c.Value); ok {
+			if err == nil {
+				dst = e.unknownConditionOperators(nested, dst)
+			}
+			return dst
+		}
+		fallthrough
+	case OperatorJSONMatch:
+		if nested, err := toRule(c.Value); err == nil {
+			dst = e.unknownOperators(nested, dst)
+		}
+	}
+	return dst
+}
+
+// Validate checks rule for problems that would otherwise only surface when it
+// is evaluated, so rules loaded at startup can fail fast: unknown operators
+// and logic, in, notin and between values of the wrong shape, invalid When
+// references and malformed groups. It reports every problem found, joined
+// with errors.Join; unknown operators wrap ErrUnknownOperator. Values that
+// refer to fields, sets or other sources are resolved at evaluation time and
+// are not checked.
+func (e *Engine) Validate(rule Rule) error {
+	var errs []error
+	if err := validateLogic(rule.Logic); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := antecedents(rule.Conditions); err != nil {
+		errs = append(errs, err)
+	}
+	for i, c := range rule.Conditions {
+		for _, err := range e.validateCondition(c) {
+			errs = append(errs, fmt.Errorf("condition %d: %w", i, err))
+		}
+	}
+	if rule.Root != nil {
+		errs = append(errs, e.validateGroup(rule.Root, "root")...)
+	}
+	return errors.Join(errs...)
+}
+
+func validateLogic(logic Logic) error {
+	switch logic {
+	case "", LogicAND, LogicOR, LogicNOT:
+		return nil
+	}
+	return fmt.Errorf("unknown logic %q", logic)
+}
+
+// validateGroup checks g and its descendants, naming each by its path from
+// the root, e.g. "root.items[1]".
+func (e *Engine) validateGroup(g *Group, path string) []error {
+	var errs []error
+	if err := validateLogic(g.Logic); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", path, err))
+	}
+	for i, item := range g.Items {
+		itemPath := fmt.Sprintf("%s.items[%d]", path, i)
+		switch {
+		case item.Condition != nil && item.Group == nil:
+			if item.Condition.When != "" {
+				errs = append(errs, fmt.Errorf("%s: when is not supported in groups", itemPath))
+			}
+			for _, err := range e.validateCondition(*item.Condition) {
+				errs = append(errs, fmt.Errorf("%s: %w", itemPath, err))
+			}
+		case item.Group != nil && item.Condition == nil:
+			errs = append(errs, e.validateGroup(item.Group, itemPath)...)
+		default:
+			errs = append(errs, fmt.Errorf("%s: must set exactly one of condition or group", itemPath))
+		}
+	}
+	return errs
+}
+
+func (e *Engine) validateCondition(c Condition) []error {
+	var errs []error
+	switch {
+	case c.Op == "" && c.OpField == "":
+		errs = append(errs, fmt.Errorf("field %q: missing operator", c.Field))
+	case c.Op != "" && !e.hasOperator(c.Op):
+		errs = append(errs, fmt.Errorf("field %q: %w %q", c.Field, ErrUnknownOperator, c.Op))
+	}
+	if c.FieldBy != nil && c.FieldBy.Discriminator == "" {
+		errs = append(errs, fmt.Errorf("fieldBy requires a discriminator"))
+	}
+	if c.ValueField != "" || isValueRef(c.Value) {
+		return errs
+	}
+	switch c.Op {
+	case OperatorIn, OperatorNotIn:
+		if _, ok := c.Value.([]any); !ok {
+			errs = append(errs, fmt.Errorf("field %q: %s requires slice value", c.Field, c.Op))
+		}
+	case OperatorBetween:
+		if _, err := betweenBounds(c.Value); err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", c.Field, err))
+		}
+	}
+	return errs
+}
+
+// isValueRef reports whether v refers to a value resolved at evaluation
+// time, such as "$field:" and "$set:" strings or a {"$counter": key} object.
+// Other strings starting with "$", such as a misspelt prefix, are plain
+// values.
+func isValueRef(v any) bool {
+	switch x := v.(type) {
+	case string:
+		if x == NowRef {
+			return true
+		}
+		for _, prefix := range []string{fieldRefPrefix, SetRefPrefix, WindowRefPrefix, QuantileRefPrefix} {
+			if strings.HasPrefix(x, prefix) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		_, ok := x[CounterRefKey]
+		return ok && len(x) == 1
+	}
+	return false
+}
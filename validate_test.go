@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want []string // substrings of the error, one per problem
+	}{
+		{
+			name: "valid rule",
+			rule: Rule{Logic: LogicOR, Conditions: []Condition{
+				{Field: "status", Op: OperatorIn, Value: []any{"active", "pending"}},
+				{Field: "age", Op: OperatorBetween, Value: []any{18, 65}},
+				{Field: "country", Op: OperatorNotIn, Value: "$set:blocked"},
+				{Field: "spend", Op: OperatorLT, ValueField: "limit", Percent: 80},
+				{Field: "amount", OpField: "amount_op", Value: 10},
+				{Field: "beta", Op: OperatorEQ, Value: true, When: "$cond:0"},
+			}},
+		},
+		{
+			name: "valid grouped rule",
+			rule: Rule{Root: &Group{Logic: LogicOR, Items: []Item{
+				{Condition: &Condition{Field: "role", Op: OperatorEQ, Value: "admin"}},
+				{Group: &Group{Items: []Item{{Condition: &Condition{Field: "age", Op: OperatorGTE, Value: 18}}}}},
+			}}},
+		},
+		{
+			name: "unknown operator",
+			rule: Rule{Conditions: []Condition{{Field: "a", Op: "approx", Value: 1}}},
+			want: []string{`condition 0: field "a": unknown operator "approx"`},
+		},
+		{
+			name: "malformed in value",
+			rule: Rule{Conditions: []Condition{{Field: "status", Op: OperatorIn, Value: "active"}}},
+			want: []string{`condition 0: field "status": in requires slice value`},
+		},
+		{
+			name: "misspelt reference",
+			rule: Rule{Conditions: []Condition{
+				{Field: "ip", Op: OperatorIn, Value: "$sett:blocked"},
+				{Field: "age", Op: OperatorBetween, Value: "$fieldd:range"},
+			}},
+			want: []string{
+				`condition 0: field "ip": in requires slice value`,
+				`condition 1: field "age": between requires [min, max] value`,
+			},
+		},
+		{
+			name: "malformed between values",
+			rule: Rule{Conditions: []Condition{
+				{Field: "age", Op: OperatorBetween, Value: []any{18}},
+				{Field: "age", Op: OperatorBetween, Value: []any{"young", 65}},
+			}},
+			want: []string{
+				`condition 0: field "age": between requires [min, max] value`,
+				`condition 1: field "age": between bound young is not a number or date`,
+			},
+		},
+		{
+			name: "all problems reported",
+			rule: Rule{Logic: "xor", Conditions: []Condition{
+				{Field: "a", Op: "approx", Value: 1},
+				{Field: "b"},
+				{Field: "c", Op: OperatorNotIn, Value: 3, When: "$cond:5"},
+			}},
+			want: []string{
+				`unknown logic "xor"`,
+				`"$cond:5" must reference an earlier condition`,
+				`condition 0: field "a": unknown operator "approx"`,
+				`condition 1: field "b": missing operator`,
+				`condition 2: field "c": notin requires slice value`,
+			},
+		},
+		{
+			name: "group problems",
+			rule: Rule{Root: &Group{Logic: "xor", Items: []Item{
+				{},
+				{Group: &Group{Items: []Item{{Condition: &Condition{Field: "a", Op: "approx", When: "$cond:0"}}}}},
+			}}},
+			want: []string{
+				`root: unknown logic "xor"`,
+				`root.items[0]: must set exactly one of condition or group`,
+				`root.items[1].items[0]: when is not supported in groups`,
+				`root.items[1].items[0]: field "a": unknown operator "approx"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.rule)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d problems, want %d:\n%v", len(lines), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+
+	t.Run("registered custom operator", func(t *testing.T) {
+		e := New()
+		e.Register("approx", func(a, b any) (bool, error) { return true, nil })
+		if err := e.Validate(Rule{Conditions: []Condition{{Field: "a", Op: "approx"}}}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("unknown operator is ErrUnknownOperator", func(t *testing.T) {
+		err := New().Validate(Rule{Conditions: []Condition{{Field: "a", Op: "approx"}}})
+		if !errors.Is(err, ErrUnknownOperator) {
+			t.Errorf("error = %v, want ErrUnknownOperator", err)
+		}
+	})
+}
+
+func TestUnmarshalRule(t *testing.T) {
+	src := []byte(`{"logic":"or","conditions":[{"field":"score","op":"approx","value":10}]}`)
+	grouped := []byte(`{"root":{"items":[{"group":{"items":[{"condition":{"field":"a","op":"fuzzy","value":1}}]}},
+		{"condition":{"field":"b","op":"approx","value":2}}]}}`)
+
+	t.Run("registered operator", func(t *testing.T) {
+		e := New()
+		e.Register("approx", func(a, b any) (bool, error) { return equalWithin(a, b, 1, false), nil })
+		rule, err := e.UnmarshalRule(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := e.Evaluate(rule, map[string]any{"score": 10.5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Matched {
+			t.Errorf("expected match: %s", res.Explanation)
+		}
+	})
+	t.Run("unregistered operator", func(t *testing.T) {
+		_, err := New().UnmarshalRule(src)
+		if !errors.Is(err, ErrUnknownOperator) {
+			t.Fatalf("error = %v, want ErrUnknownOperator", err)
+		}
+		if !strings.Contains(err.Error(), `"approx"`) {
+			t.Errorf("error = %q, want it to name approx", err)
+		}
+	})
+	t.Run("lists every unknown operator in groups", func(t *testing.T) {
+		_, err := New().UnmarshalRule(grouped)
+		if want := `unknown operator: "approx", "fuzzy"`; err == nil || err.Error() != want {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	})
+	t.Run("lists unknown operators in nested rules", func(t *testing.T) {
+		nested := []byte(`{"conditions":[
+			{"field":"items","op":"all","value":{"conditions":[{"field":"sku","op":"approx","value":1}]}},
+			{"field":"tags","op":"any","value":{"op":"fuzzy","value":"x"}},
+			{"field":"payload","op":"jsonmatch","value":{"root":{"items":[{"condition":{"field":"a","op":"near","value":1}}]}}}]}`)
+		_, err := New().UnmarshalRule(nested)
+		if want := `unknown operator: "approx", "fuzzy", "near"`; err == nil || err.Error() != want {
+			t.Errorf("error = %v, want %q", err, want)
+		}
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		if _, err := New().UnmarshalRule([]byte(`{"conditions":`)); err == nil {
+			t.Error("expected error for invalid JSON")
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// OperatorVariant deterministically assigns the field value, typically a
+// user ID, to one of several weighted variants and matches on the assigned
+// variant. The condition value is
+//
+//	{"variants":{"A":50,"B":50},"salt":"exp1","variant":"B"}
+//
+// where weights are relative, salt keeps assignments of separate experiments
+// independent, and variant is the variant to match; without it the condition
+// always matches. The same field value and salt always get the same variant,
+// which is recorded in Result.Variants under the salt.
+const OperatorVariant Operator = "variant"
+
+func (e *Engine) variant(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	v, want, err := e.operands(ctx, st, c, data)
+	if err != nil {
+		return false, err
+	}
+	spec, ok := want.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf(`variant requires {"variants":{...},"salt":...} value`)
+	}
+	weights, ok := spec["variants"].(map[string]any)
+	if !ok || len(weights) == 0 {
+		return false, fmt.Errorf("variant requires a non-empty variants object")
+	}
+	salt, _ := spec["salt"].(string)
+	assigned, err := assignVariant(weights, salt, fmt.Sprint(v))
+	if err != nil {
+		return false, err
+	}
+	if st.variants == nil {
+		st.variants = make(map[string]string)
+	}
+	st.variants[salt] = assigned
+	target, ok := spec["variant"]
+	if !ok {
+		return true, nil
+	}
+	return target == assigned, nil
+}
+
+// assignVariant hashes salt and key to a point in [0, total weight) and
+// returns the variant whose cumulative weight range contains it. Variants
+// are ordered by name so assignment does not depend on map order.
+func assignVariant(weights map[string]any, salt, key string) (string, error) {
+	names := make([]string, 0, len(weights))
+	var total float64
+	for name, w := range weights {
+		f, ok := toFloat(w)
+		if !ok || f < 0 {
+			return "", fmt.Errorf("variant %q requires a non-negative numeric weight", name)
+		}
+		names = append(names, name)
+		total += f
+	}
+	if total <= 0 {
+		return "", fmt.Errorf("variant weights must sum to more than zero")
+	}
+	slices.Sort(names)
+	sum := sha256.Sum256([]byte(salt + "\x00" + key))
+	point := float64(binary.BigEndian.Uint64(sum[:8])) / math.Exp2(64) * total
+	var cum float64
+	for _, name := range names {
+		w, _ := toFloat(weights[name])
+		cum += w
+		if point < cum {
+			return name, nil
+		}
+	}
+	// Rounding can leave point at total; it belongs to the last weighted
+	// variant.
+	for i := len(names) - 1; ; i-- {
+		if w, _ := toFloat(weights[names[i]]); w > 0 {
+			return names[i], nil
+		}
+	}
+}
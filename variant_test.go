@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestVariantDistribution(t *testing.T) {
+	e := New()
+	spec := map[string]any{"variants": map[string]any{"A": 20, "B": 80}, "salt": "exp1"}
+	counts := map[string]int{}
+	const n = 10000
+	for i := range n {
+		res, err := e.Evaluate(Rule{Conditions: []Condition{{Field: "user", Op: OperatorVariant, Value: spec}}}, map[string]any{"user": fmt.Sprintf("user-%d", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Matched {
+			t.Fatal("variant without a target should always match")
+		}
+		counts[res.Variants["exp1"]]++
+	}
+	if share := float64(counts["A"]) / n; math.Abs(share-0.2) > 0.02 {
+		t.Errorf("share of A = %.3f, want about 0.2 (counts %v)", share, counts)
+	}
+	if counts["A"]+counts["B"] != n {
+		t.Errorf("unexpected variants assigned: %v", counts)
+	}
+}
+
+func TestVariantSticky(t *testing.T) {
+	spec := map[string]any{"variants": map[string]any{"A": 50, "B": 50}, "salt": "exp1"}
+	rule := Rule{Conditions: []Condition{{Field: "user", Op: OperatorVariant, Value: spec}}}
+	data := map[string]any{"user": "alice"}
+	first, err := New().Evaluate(rule, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assigned := first.Variants["exp1"]
+	for range 5 {
+		res, err := New().Evaluate(rule, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Variants["exp1"] != assigned {
+			t.Fatalf("variant changed from %q to %q", assigned, res.Variants["exp1"])
+		}
+	}
+
+	for _, target := range []string{"A", "B"} {
+		spec := map[string]any{"variants": spec["variants"], "salt": "exp1", "variant": target}
+		res, err := New().Evaluate(Rule{Conditions: []Condition{{Field: "user", Op: OperatorVariant, Value: spec}}}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Matched != (target == assigned) {
+			t.Errorf("variant %q matched = %v, assigned %q", target, res.Matched, assigned)
+		}
+	}
+}
+
+func TestVariantErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"not an object", "A"},
+		{"no variants", map[string]any{"salt": "x"}},
+		{"negative weight", map[string]any{"variants": map[string]any{"A": -1, "B": 2}}},
+		{"zero total", map[string]any{"variants": map[string]any{"A": 0}}},
+		{"non numeric weight", map[string]any{"variants": map[string]any{"A": "half"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Evaluate(Rule{Conditions: []Condition{{Field: "user", Op: OperatorVariant, Value: tt.value}}}, map[string]any{"user": "u"})
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
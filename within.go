@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// OperatorWithinPct matches when a numeric field lies within Pct percent of
+// the condition value, typically another field:
+// {"field":"reading","op":"within_pct","valueField":"setpoint","pct":5}
+// matches when |reading - setpoint| <= |setpoint| * 5 / 100. A zero setpoint
+// therefore only matches a zero reading.
+const OperatorWithinPct Operator = "within_pct"
+
+func (e *Engine) withinPct(ctx context.Context, st *evalState, c Condition, data map[string]any) (bool, error) {
+	v, want, err := e.operands(ctx, st, c, data)
+	if err != nil {
+		return false, err
+	}
+	fv, okv := toFloat(v)
+	fw, okw := toFloat(want)
+	if !okv || !okw {
+		return false, fmt.Errorf("type mismatch for within_pct")
+	}
+	if c.Pct < 0 {
+		return false, fmt.Errorf("within_pct requires a non-negative pct")
+	}
+	return math.Abs(fv-fw) <= math.Abs(fw)*c.Pct/100, nil
+}
+
+// OperatorMultipleOf matches a numeric field within a tolerance of an
+// integer multiple of a base, given as {"multiple":0.5,"tolerance":0.01}.
+// A bare number is a multiple with zero tolerance; as with any float
+// comparison, a small tolerance is advisable for fractional bases.
+const OperatorMultipleOf Operator = "multiple_of"
+
+func multipleOf(a, b any) (bool, error) {
+	f, ok := toFloat(a)
+	if !ok {
+		return false, fmt.Errorf("type mismatch for multiple_of")
+	}
+	base, tol := b, any(0)
+	if spec, ok := b.(map[string]any); ok {
+		base, tol = spec["multiple"], spec["tolerance"]
+		if tol == nil {
+			tol = 0
+		}
+	}
+	m, okm := toFloat(base)
+	t, okt := toFloat(tol)
+	if !okm || !okt || m == 0 || t < 0 {
+		return false, fmt.Errorf(`multiple_of requires {"multiple":N,"tolerance":T} value with non-zero N and non-negative T`)
+	}
+	return math.Abs(math.Remainder(f, m)) <= t, nil
+}
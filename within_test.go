@@ -0,0 +1,73 @@
+package rules
+
+import "testing"
+
+func TestWithinPct(t *testing.T) {
+	e := New()
+	cond := Condition{Field: "reading", Op: OperatorWithinPct, ValueField: "setpoint", Pct: 5}
+	tests := []struct {
+		name    string
+		data    map[string]any
+		want    bool
+		wantErr bool
+	}{
+		{"inside band", map[string]any{"reading": 103, "setpoint": 100}, true, false},
+		{"on lower edge", map[string]any{"reading": 95, "setpoint": 100}, true, false},
+		{"above band", map[string]any{"reading": 105.5, "setpoint": 100}, false, false},
+		{"below band", map[string]any{"reading": 90, "setpoint": 100}, false, false},
+		{"negative setpoint", map[string]any{"reading": -98, "setpoint": -100}, true, false},
+		{"zero setpoint exact", map[string]any{"reading": 0, "setpoint": 0}, true, false},
+		{"zero setpoint drift", map[string]any{"reading": 0.01, "setpoint": 0}, false, false},
+		{"numeric strings", map[string]any{"reading": "101", "setpoint": "100"}, true, false},
+		{"non numeric", map[string]any{"reading": "high", "setpoint": 100}, false, true},
+		{"missing setpoint", map[string]any{"reading": 100}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := e.Evaluate(Rule{Conditions: []Condition{cond}}, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultipleOf(t *testing.T) {
+	spec := map[string]any{"multiple": 0.5, "tolerance": 0.01}
+	tests := []struct {
+		name    string
+		length  any
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{"on multiple", 2.5, spec, true, false},
+		{"zero", 0, spec, true, false},
+		{"near multiple above", 3.008, spec, true, false},
+		{"near multiple below", 2.991, spec, true, false},
+		{"off multiple", 2.75, spec, false, false},
+		{"just outside tolerance", 3.02, spec, false, false},
+		{"negative", -1.5, spec, true, false},
+		{"bare multiple", 12, 4, true, false},
+		{"bare multiple off", 13, 4, false, false},
+		{"tolerance omitted", 1.5, map[string]any{"multiple": 0.5}, true, false},
+		{"zero multiple", 1, map[string]any{"multiple": 0}, false, true},
+		{"negative tolerance", 1, map[string]any{"multiple": 1, "tolerance": -1}, false, true},
+		{"non numeric field", "long", spec, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Conditions: []Condition{{Field: "length", Op: OperatorMultipleOf, Value: tt.value}}}
+			res, err := Evaluate(rule, map[string]any{"length": tt.length})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if res.Matched != tt.want {
+				t.Errorf("Matched = %v, want %v", res.Matched, tt.want)
+			}
+		})
+	}
+}